@@ -0,0 +1,50 @@
+package zlog
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/rs/zerolog"
+)
+
+type change struct {
+	from, to string
+}
+
+// Change returns an object for a value changed from "from" to "to", e.g. log.Info().Object("mode", zlog.Change("a", "b")).
+// JSON contains {"mode":{"old":"a","new":"b"}}, the console formats show mode=a→b with the old
+// value in red and the new value in green, or mode=a->b without colors.
+func Change(from, to string) zerolog.LogObjectMarshaler {
+	return change{from: from, to: to}
+}
+
+func (c change) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("old", c.from).Str("new", c.to)
+}
+
+// Change adds a changed value, see Change()
+func (e *Error) Change(name, from, to string) *Error {
+	return e.add(func(c zerolog.Context) zerolog.Context { return c.Object(name, Change(from, to)) })
+}
+
+// Returns the console value for a field added with Change(), the ConsoleWriter passes objects as
+// JSON with sorted keys. p is nil without colors.
+func formatChange(i interface{}, p *palette) (string, bool) {
+	b, ok := i.([]byte)
+	if !ok || !bytes.HasPrefix(b, []byte(`{"new":`)) {
+		return "", false
+	}
+	var c map[string]string
+	if err := json.Unmarshal(b, &c); err != nil || len(c) != 2 {
+		return "", false
+	}
+	from, okFrom := c["old"]
+	to, okTo := c["new"]
+	if !okFrom || !okTo {
+		return "", false
+	}
+	if p == nil {
+		return from + "->" + to, true
+	}
+	return p.error + from + ResetColor + "→" + p.info + to + ResetColor, true
+}
@@ -0,0 +1,33 @@
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestChange(t *testing.T) {
+	l, buf := NewTest(Options{Format: FormatBW, TimeFormat: "none"})
+	l.Info().Object("mode", Change("debug", "production")).Msg("Config changed")
+	if expected := "INF Config changed mode=debug->production\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	l, buf = NewTest(Options{Format: FormatColor, ForceColor: true, TimeFormat: "none"})
+	l.Info().Object("mode", Change("debug", "production")).Msg("Config changed")
+	if !strings.Contains(buf.String(), "mode="+ResetColor+Red+"debug"+ResetColor+"→"+Green+"production"+ResetColor) {
+		t.Errorf("expected colored change in %q", buf.String())
+	}
+
+	var js bytes.Buffer
+	tee := TeeWriter(&js, Options{Format: FormatJson})
+	tee.Info().Object("mode", Change("debug", "production")).Msg("Config changed")
+	if !strings.Contains(js.String(), `"mode":{"old":"debug","new":"production"}`) {
+		t.Errorf("expected object in %q", js.String())
+	}
+
+	e := NewErrorNoStack("Config rejected").Change("port", "22", "2222")
+	if expected := "Config rejected port=22->2222"; e.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, e.Error())
+	}
+}
@@ -0,0 +1,39 @@
+package zlog
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Times of the checkpoints set with Checkpoint()
+var (
+	checkpointsMu sync.Mutex
+	checkpoints   = map[string]time.Time{}
+)
+
+// Checkpoint marks the current time with name for Since(), setting a checkpoint again restarts it
+func Checkpoint(name string) {
+	checkpointsMu.Lock()
+	defer checkpointsMu.Unlock()
+	checkpoints[name] = now()
+}
+
+// Since returns an info event of the global logger with the time since the checkpoint name as
+// fields elapsed and since, e.g. zlog.Since("phase1").Msg("Loaded data") logs
+// "INF Loaded data elapsed=123 since=phase1". The elapsed time is measured from StartTime() if the
+// checkpoint is not set.
+func Since(name string) *zerolog.Event {
+	checkpointsMu.Lock()
+	start, ok := checkpoints[name]
+	checkpointsMu.Unlock()
+	if !ok {
+		start = StartTime()
+	}
+	loggerMu.RLock()
+	l := log.Logger
+	loggerMu.RUnlock()
+	return l.Info().Dur("elapsed", now().Sub(start)).Str("since", name)
+}
@@ -0,0 +1,31 @@
+package zlog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func TestSince(t *testing.T) {
+	defer func(l zerolog.Logger, clock func() time.Time, startup time.Time) {
+		log.Logger, now = l, clock
+		SetStartTime(startup)
+	}(log.Logger, now, StartTime())
+	clock := time.Date(2022, 2, 6, 12, 0, 0, 0, time.Local)
+	now = func() time.Time { return clock }
+	SetStartTime(clock.Add(-time.Minute))
+
+	l, buf := NewTest(Options{Format: FormatBW, TimeFormat: "none", DurationUnit: time.Millisecond})
+	log.Logger = l
+	Checkpoint("phase1")
+	clock = clock.Add(123 * time.Millisecond)
+	Since("phase1").Msg("Loaded data")
+	Since("unknown").Msg("Since start")
+
+	expected := "INF Loaded data elapsed=123ms since=phase1\nINF Since start elapsed=1m0.123s since=unknown\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
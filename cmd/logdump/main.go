@@ -1,3 +1,61 @@
 package main
 
 // Dump JSON logfiles in console mode
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"github.com/fpunkt/zlog"
+)
+
+var options = struct {
+	format     string
+	timeformat string
+}{}
+
+var formats = map[string]zlog.LogOutputFormat{
+	"color":   zlog.FormatColor,
+	"bw":      zlog.FormatBW,
+	"unicode": zlog.FormatUnicode,
+}
+
+func main() {
+	pflag.StringVarP(&options.format, "format", "f", "color", "output format: color, bw or unicode")
+	pflag.StringVarP(&options.timeformat, "time", "t", "default", `time format: "s", "ms", "us", "none", "default", "highres" or a golang time format`)
+	pflag.Parse()
+
+	format, ok := formats[options.format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "logdump: unknown format %q\n", options.format)
+		os.Exit(2)
+	}
+	o := zlog.Options{Format: format, TimeFormat: options.timeformat, ForceColor: format != zlog.FormatBW}
+
+	if pflag.NArg() == 0 {
+		dump("-", o)
+		return
+	}
+	for _, fname := range pflag.Args() {
+		dump(fname, o)
+	}
+}
+
+func dump(fname string, o zlog.Options) {
+	in := os.Stdin
+	if fname != "-" {
+		fd, err := os.Open(fname)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logdump: %s\n", err)
+			os.Exit(1)
+		}
+		defer fd.Close()
+		in = fd
+	}
+	if err := zlog.Logdump(in, os.Stdout, o); err != nil {
+		fmt.Fprintf(os.Stderr, "logdump: %s: %s\n", fname, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+// +build !windows
+
+package zlog
+
+import "os"
+
+// Unix terminals support ANSI colors, except for the "dumb" terminal (e.g. Emacs shell buffers).
+// Redirected output, NO_COLOR and FORCE_COLOR are checked per logger, see colorsEnabled().
+func init() {
+	SupportColors = termSupportsColors(os.Getenv("TERM"))
+}
+
+func termSupportsColors(term string) bool {
+	return term != "dumb"
+}
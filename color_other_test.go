@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package zlog
+
+import "testing"
+
+func TestTermSupportsColors(t *testing.T) {
+	for term, expected := range map[string]bool{"xterm-256color": true, "screen": true, "": true, "dumb": false} {
+		if termSupportsColors(term) != expected {
+			t.Errorf("TERM=%q: expected %t", term, expected)
+		}
+	}
+}
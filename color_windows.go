@@ -1,7 +1,6 @@
 package zlog
 
 import (
-	"fmt"
 	"os"
 	"strings"
 	"syscall"
@@ -16,9 +15,9 @@ func init() {
 	}
 
 	SupportColors = false
+	// on failure silently stay in BW mode, printing would corrupt the output of the program
 	outHandle, err := syscall.Open("CONOUT$", syscall.O_RDWR, 0)
 	if err != nil {
-		fmt.Println(53, err)
 		return
 	}
 
@@ -30,13 +29,15 @@ func init() {
 
 	var mode uint32
 	if err = syscall.GetConsoleMode(outHandle, &mode); err != nil {
-		fmt.Println(92, err)
 		return
 	}
 
 	const EnableVirtualTerminalProcessingMode uint32 = 0x4
 	mode |= EnableVirtualTerminalProcessingMode
 
-	procSetConsoleMode.Call(uintptr(outHandle), uintptr(mode))
+	// SetConsoleMode returns 0 on failure, e.g. on old consoles without ANSI support
+	if r, _, _ := procSetConsoleMode.Call(uintptr(outHandle), uintptr(mode)); r == 0 {
+		return
+	}
 	SupportColors = true
 }
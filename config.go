@@ -0,0 +1,75 @@
+package zlog
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// options has the fields of Options without the methods to avoid recursion while decoding
+type options Options
+
+// UnmarshalJSON decodes Options from a config file like {"level": "debug", "format": "unicode",
+// "timeformat": "highres"}. Keys are matched case-insensitively to the field names, the level can
+// be a name for ParseLevel or a number and the format a name for ParseFormat. Fields that are not
+// present keep their value. Out, Sampler and Hooks can't be set in config files.
+func (o *Options) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		*options
+		Level interface{}
+	}{options: (*options)(o)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	switch level := aux.Level.(type) {
+	case nil:
+	case float64:
+		o.Level = int(level)
+	case string:
+		l, err := ParseLevel(level)
+		if err != nil {
+			return err
+		}
+		o.Level = l
+	default:
+		return fmt.Errorf("invalid log level %v", level)
+	}
+	return nil
+}
+
+// UnmarshalYAML decodes Options from YAML like {level: debug, format: unicode, timeformat: highres}
+// with the rules of UnmarshalJSON. The signature is the one of gopkg.in/yaml.v2, which is also
+// supported by yaml.v3, to avoid the dependency.
+func (o *Options) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var m map[string]interface{}
+	if err := unmarshal(&m); err != nil {
+		return err
+	}
+	data, err := json.Marshal(jsonValue(m))
+	if err != nil {
+		return err
+	}
+	return o.UnmarshalJSON(data)
+}
+
+// jsonValue converts the map[interface{}]interface{} of yaml.v2 to maps that json can encode
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = jsonValue(value)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = jsonValue(value)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = jsonValue(v[i])
+		}
+	}
+	return v
+}
@@ -0,0 +1,63 @@
+package zlog
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUnmarshalOptions(t *testing.T) {
+	var o Options
+	if err := json.Unmarshal([]byte(`{"level": "debug", "format": "unicode", "timeformat": "highres", "hidefields": ["trace_id"]}`), &o); err != nil {
+		t.Fatal(err)
+	}
+	expected := Options{Level: 1, Format: FormatUnicode, TimeFormat: "highres", HideFields: []string{"trace_id"}}
+	if !reflect.DeepEqual(o, expected) {
+		t.Errorf("expected %+v, got %+v", expected, o)
+	}
+
+	if err := json.Unmarshal([]byte(`{"level": -2}`), &o); err != nil || o.Level != -2 || o.Format != FormatUnicode {
+		t.Errorf("expected level -2 and unchanged format, got %+v, %v", o, err)
+	}
+
+	for _, data := range []string{`{"level": "verbose"}`, `{"level": true}`, `{"format": "fancy"}`} {
+		if err := json.Unmarshal([]byte(data), &o); err == nil {
+			t.Errorf("expected error for %s", data)
+		}
+	}
+}
+
+func TestMarshalOptions(t *testing.T) {
+	fileLevel := -1
+	o := Options{Level: 2, Format: FormatJsonStd, TimeFormat: "rfc3339", FileLevel: &fileLevel, DurationUnit: time.Millisecond,
+		LevelColors: map[string]string{"warn": "yellow"}, FieldNames: StdFieldNames}
+	data, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Options
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("%s: %v", data, err)
+	}
+	if !reflect.DeepEqual(o, decoded) {
+		t.Errorf("expected %+v, got %+v", o, decoded)
+	}
+}
+
+func TestUnmarshalYAMLOptions(t *testing.T) {
+	// what yaml.v2 decodes for {level: debug, format: unicode, timeformat: highres, levelcolors: {warn: yellow}}
+	yaml := func(v interface{}) error {
+		*v.(*map[string]interface{}) = map[string]interface{}{"level": "debug", "format": "unicode", "timeformat": "highres",
+			"levelcolors": map[interface{}]interface{}{"warn": "yellow"}}
+		return nil
+	}
+	var o Options
+	if err := o.UnmarshalYAML(yaml); err != nil {
+		t.Fatal(err)
+	}
+	expected := Options{Level: 1, Format: FormatUnicode, TimeFormat: "highres", LevelColors: map[string]string{"warn": "yellow"}}
+	if !reflect.DeepEqual(o, expected) {
+		t.Errorf("expected %+v, got %+v", expected, o)
+	}
+}
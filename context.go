@@ -0,0 +1,23 @@
+package zlog
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+type loggerKey struct{}
+
+// Into returns a copy of ctx carrying the logger l, use From() to retrieve it
+func Into(ctx context.Context, l zerolog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// From returns the logger stored with Into() or the global logger if ctx carries no logger
+func From(ctx context.Context) zerolog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(zerolog.Logger); ok {
+		return l
+	}
+	return log.Logger
+}
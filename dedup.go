@@ -0,0 +1,130 @@
+package zlog
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// DedupFieldName is the field name for the number of suppressed messages, see Dedup()
+var DedupFieldName = "repeated"
+
+// Dedup returns a hook suppressing identical messages (same level and message) within window,
+// e.g. for error loops. The first message of a window is logged, when the window expires a
+// summary with the message and the number of suppressed messages as field "repeated" is written
+// to out, usually the logger the hook is added to:
+//
+//	l = l.Hook(zlog.Dedup(time.Second, l))
+//
+// See Options.Dedup for loggers created with New().
+func Dedup(window time.Duration, out zerolog.Logger) zerolog.Hook {
+	return &dedupHook{window: window, out: out, seen: map[dedupKey]*dedupEntry{}}
+}
+
+type dedupKey struct {
+	level zerolog.Level
+	msg   string
+}
+
+type dedupEntry struct {
+	start      time.Time // start of the window
+	suppressed int
+}
+
+type dedupHook struct {
+	window  time.Duration
+	out     zerolog.Logger // for the summaries
+	mu      sync.Mutex
+	seen    map[dedupKey]*dedupEntry
+	pruned  time.Time
+	timer   *time.Timer // flushes the summaries if no more messages are logged
+	flushAt time.Time
+}
+
+func (h *dedupHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if level == zerolog.Disabled {
+		return
+	}
+	t := now()
+	h.mu.Lock()
+	var summaries map[dedupKey]int
+	if t.Sub(h.pruned) >= h.window {
+		summaries = h.expire(t)
+	}
+	key := dedupKey{level, msg}
+	entry, ok := h.seen[key]
+	switch {
+	case !ok:
+		h.seen[key] = &dedupEntry{start: t}
+	case t.Sub(entry.start) < h.window:
+		entry.suppressed++
+		e.Discard()
+		h.schedule(entry.start.Add(h.window), t)
+	default:
+		// the window expired before the entry was dropped
+		if entry.suppressed > 0 {
+			if summaries == nil {
+				summaries = map[dedupKey]int{}
+			}
+			summaries[key] = entry.suppressed
+		}
+		entry.start, entry.suppressed = t, 0
+	}
+	h.mu.Unlock()
+	h.summarize(summaries)
+}
+
+// Writes the summaries of the expired windows, called by the timer
+func (h *dedupHook) flush() {
+	h.mu.Lock()
+	t := now()
+	summaries := h.expire(t)
+	h.timer = nil
+	// restart the timer for the windows that have not expired yet
+	for _, entry := range h.seen {
+		if entry.suppressed > 0 {
+			h.schedule(entry.start.Add(h.window), t)
+		}
+	}
+	h.mu.Unlock()
+	h.summarize(summaries)
+}
+
+// Makes sure the timer flushes the summaries at the latest at the given time
+func (h *dedupHook) schedule(at, t time.Time) {
+	switch {
+	case h.timer == nil:
+		h.timer = time.AfterFunc(at.Sub(t), h.flush)
+	case at.Before(h.flushAt):
+		h.timer.Reset(at.Sub(t))
+	default:
+		return
+	}
+	h.flushAt = at
+}
+
+// Drops the expired windows and returns the number of suppressed messages of these windows
+func (h *dedupHook) expire(t time.Time) map[dedupKey]int {
+	var summaries map[dedupKey]int
+	for key, entry := range h.seen {
+		if t.Sub(entry.start) < h.window {
+			continue
+		}
+		if entry.suppressed > 0 {
+			if summaries == nil {
+				summaries = map[dedupKey]int{}
+			}
+			summaries[key] = entry.suppressed
+		}
+		delete(h.seen, key)
+	}
+	h.pruned = t
+	return summaries
+}
+
+func (h *dedupHook) summarize(summaries map[dedupKey]int) {
+	for key, suppressed := range summaries {
+		h.out.WithLevel(key.level).Int(DedupFieldName, suppressed).Msg(key.msg)
+	}
+}
@@ -0,0 +1,107 @@
+package zlog
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDedup(t *testing.T) {
+	defer func(clock func() time.Time) { now = clock }(now)
+	clock := time.Date(2022, 2, 6, 12, 34, 56, 0, time.Local)
+	now = func() time.Time { return clock }
+
+	l, buf := NewTest(Options{Format: FormatBW, TimeFormat: "none"})
+	h := Dedup(time.Second, l).(*dedupHook)
+	defer stopDedup(h)
+	l = l.Hook(h)
+	for i := 0; i < 1000; i++ {
+		l.Error().Msg("Connection refused")
+		clock = clock.Add(time.Millisecond / 2)
+	}
+	l.Warn().Msg("Connection refused") // other level
+	l.Error().Msg("Timeout")
+	clock = clock.Add(time.Second)
+	l.Error().Msg("Connection refused")
+	l.Error().Msg("Connection refused")
+
+	expected := "ERR Connection refused\n" +
+		"WRN Connection refused\n" +
+		"ERR Timeout\n" +
+		"ERR Connection refused repeated=999\n" +
+		"ERR Connection refused\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestDedupExpiredEntry(t *testing.T) {
+	defer func(clock func() time.Time) { now = clock }(now)
+	start := time.Date(2022, 2, 6, 12, 34, 56, 0, time.Local)
+	clock := start
+	now = func() time.Time { return clock }
+
+	l, buf := NewTest(Options{Format: FormatBW, TimeFormat: "none"})
+	h := Dedup(time.Second, l).(*dedupHook)
+	defer stopDedup(h)
+	l = l.Hook(h)
+	for _, m := range []struct {
+		at  time.Duration
+		msg string
+	}{{0, "X"}, {500 * time.Millisecond, "A"}, {700 * time.Millisecond, "A"}, {time.Second, "Y"}, {1600 * time.Millisecond, "A"}} {
+		clock = start.Add(m.at)
+		l.Info().Msg(m.msg)
+	}
+
+	// the window of A expired but A was not dropped with X at 1.0s
+	expected := "INF X\nINF A\nINF Y\nINF A repeated=1\nINF A\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+// Stops the timer of h, it would read the clock of later tests
+func stopDedup(h *dedupHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.timer != nil {
+		h.timer.Stop()
+	}
+}
+
+// bytes.Buffer for concurrent writes by the Dedup timer
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestDedupSummaryAfterSpam(t *testing.T) {
+	var buf lockedBuffer
+	o := Options{Format: FormatBW, TimeFormat: "none", Out: &buf, Dedup: 20 * time.Millisecond}
+	l := newLocalLogger(o)
+	for i := 0; i < 10; i++ {
+		l.Error().Msg("Connection refused")
+	}
+
+	// the spam stops, the timer writes the summary
+	expected := "ERR Connection refused\nERR Connection refused repeated=9\n"
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if buf.String() == expected {
+			return
+		}
+	}
+	t.Errorf("expected %q, got %q", expected, buf.String())
+}
@@ -0,0 +1,62 @@
+package zlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// DurationFieldNames are the names of the fields shown like "1.5s" in the console formats with
+// Options.DurationUnit, e.g. for log.Info().Dur("elapsed", d)
+var DurationFieldNames = []string{"duration", "elapsed", "latency", "timeout"}
+
+// Returns a writer rewriting the duration fields of events to strings like "1.5s" for the console
+// writer w if Options.DurationUnit is set
+func consoleDurations(w io.Writer, o Options) io.Writer {
+	if o.DurationUnit <= 0 {
+		return w
+	}
+	return durationWriter{out: w}
+}
+
+type durationWriter struct {
+	out io.Writer
+}
+
+func (w durationWriter) Write(p []byte) (int, error) {
+	found := false
+	for _, name := range DurationFieldNames {
+		if bytes.Contains(p, []byte(`"`+name+`":`)) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return w.out.Write(p)
+	}
+	var evt map[string]json.RawMessage
+	if err := json.Unmarshal(p, &evt); err != nil {
+		return 0, fmt.Errorf("cannot decode event: %s", err)
+	}
+	for _, name := range DurationFieldNames {
+		if raw, ok := evt[name]; ok {
+			if f, err := strconv.ParseFloat(string(raw), 64); err == nil {
+				// the unit the event was encoded with, which is o.DurationUnit for New() only
+				evt[name], _ = json.Marshal(time.Duration(f * float64(zerolog.DurationFieldUnit)).String())
+			}
+		}
+	}
+	b, err := json.Marshal(evt)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := w.out.Write(b); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
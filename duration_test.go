@@ -0,0 +1,53 @@
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestDurationUnit(t *testing.T) {
+	defer func(unit time.Duration, integer bool) {
+		zerolog.DurationFieldUnit, zerolog.DurationFieldInteger = unit, integer
+	}(zerolog.DurationFieldUnit, zerolog.DurationFieldInteger)
+
+	var buf, js bytes.Buffer
+	l := New(Options{Format: FormatBW, TimeFormat: "none", Out: &buf, DurationUnit: time.Millisecond})
+	l.Info().Dur("elapsed", 123*time.Millisecond).Dur("timeout", 1500*time.Millisecond).Int("n", 3).Msg("done")
+	if expected := "INF done elapsed=123ms n=3 timeout=1.5s\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	tee := TeeWriter(&js, Options{Format: FormatJson})
+	tee.Info().Dur("elapsed", 250*time.Microsecond).Dur("latency", 62*time.Second).Msg("json")
+	if !strings.Contains(js.String(), `"elapsed":0.25,"latency":62000`) {
+		t.Errorf("expected durations in ms in %q", js.String())
+	}
+	if !strings.Contains(buf.String(), `elapsed="250µs" latency=1m2s`) {
+		t.Errorf("expected formatted durations in %q", buf.String())
+	}
+
+	buf.Reset()
+	l = New(Options{Format: FormatBW, TimeFormat: "none", Out: &buf, DurationUnit: time.Second})
+	l.Info().Dur("duration", 2500*time.Millisecond).Msg("seconds")
+	if expected := "INF seconds duration=2.5s\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestDurationUnitNewTest(t *testing.T) {
+	defer func(unit time.Duration, integer bool) {
+		zerolog.DurationFieldUnit, zerolog.DurationFieldInteger = unit, integer
+	}(zerolog.DurationFieldUnit, zerolog.DurationFieldInteger)
+	zerolog.DurationFieldUnit = time.Millisecond
+
+	// NewTest keeps the global unit, the console shows the durations as encoded
+	l, buf := NewTest(Options{Format: FormatBW, TimeFormat: "none", DurationUnit: time.Second})
+	l.Info().Dur("elapsed", 1500*time.Millisecond).Msg("done")
+	if expected := "INF done elapsed=1.5s\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
@@ -0,0 +1,32 @@
+package zlog
+
+import (
+	"os"
+
+	"github.com/rs/zerolog/log"
+)
+
+// OptionsFromEnv returns Options configured through the environment variables ZLOG_LEVEL (a level
+// name for ParseLevel), ZLOG_FORMAT (a format name for ParseFormat like color, bw, unicode or
+// compact) and ZLOG_TIMEFORMAT (see Options.TimeFormat). Unset variables keep the defaults. An
+// unknown level name is passed on as LevelName, so New warns about it; unknown formats are ignored.
+func OptionsFromEnv() Options {
+	var o Options
+	if s, ok := os.LookupEnv("ZLOG_LEVEL"); ok {
+		if level, err := ParseLevel(s); err == nil {
+			o.Level = level
+		} else {
+			o.LevelName = s
+		}
+	}
+	if s, ok := os.LookupEnv("ZLOG_FORMAT"); ok {
+		if format, err := ParseFormat(s); err == nil {
+			o.Format = format
+		}
+	}
+	o.TimeFormat = os.Getenv("ZLOG_TIMEFORMAT")
+	return o
+}
+
+// InitFromEnv sets the global zerolog.log.Logger to a console logger configured by OptionsFromEnv()
+func InitFromEnv() { log.Logger = New(OptionsFromEnv()) }
@@ -0,0 +1,36 @@
+package zlog
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestOptionsFromEnv(t *testing.T) {
+	for _, name := range []string{"ZLOG_LEVEL", "ZLOG_FORMAT", "ZLOG_TIMEFORMAT"} {
+		defer os.Unsetenv(name)
+	}
+	for _, test := range []struct {
+		name, value string
+		expected    Options
+	}{
+		{"", "", Options{}},
+		{"ZLOG_LEVEL", "debug", Options{Level: 1}},
+		{"ZLOG_LEVEL", "WARN", Options{Level: -1}},
+		{"ZLOG_LEVEL", "verbose", Options{LevelName: "verbose"}},
+		{"ZLOG_FORMAT", "unicode", Options{Format: FormatUnicode}},
+		{"ZLOG_FORMAT", "JSON", Options{Format: FormatJson}},
+		{"ZLOG_FORMAT", "fancy", Options{}},
+		{"ZLOG_TIMEFORMAT", "highres", Options{TimeFormat: "highres"}},
+	} {
+		if test.name != "" {
+			os.Setenv(test.name, test.value)
+		}
+		if o := OptionsFromEnv(); !reflect.DeepEqual(o, test.expected) {
+			t.Errorf("%s=%q: expected %+v, got %+v", test.name, test.value, test.expected, o)
+		}
+		if test.name != "" {
+			os.Unsetenv(test.name)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package zlog_test
+
+import (
+	"fmt"
+
+	"github.com/fpunkt/zlog"
+)
+
+func ExampleNewTest() {
+	l, buf := zlog.NewTest(zlog.Options{Format: zlog.FormatBW, TimeFormat: "none"})
+	l.Info().Str("file", "hosts").Msg("Creating file")
+	fmt.Print(buf.String())
+	// Output: INF Creating file file=hosts
+}
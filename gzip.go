@@ -0,0 +1,40 @@
+package zlog
+
+import (
+	"compress/gzip"
+	"os"
+	"sync"
+)
+
+// gzipFile compresses the logfile written with Options.Gzip. Each message is flushed, so the
+// messages up to a log.Fatal() or os.Exit() are in the file. Close() must be called to write the
+// gzip trailer.
+type gzipFile struct {
+	mu sync.Mutex
+	gz *gzip.Writer
+	fd *os.File
+}
+
+func newGzipFile(fd *os.File) *gzipFile {
+	return &gzipFile{gz: gzip.NewWriter(fd), fd: fd}
+}
+
+func (f *gzipFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n, err := f.gz.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, f.gz.Flush()
+}
+
+func (f *gzipFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.gz.Close(); err != nil {
+		f.fd.Close()
+		return err
+	}
+	return f.fd.Close()
+}
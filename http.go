@@ -0,0 +1,23 @@
+package zlog
+
+import "net/http"
+
+// RequestIDHeader is the header with the request id for HTTPFields()
+var RequestIDHeader = "X-Request-Id"
+
+// HTTPFields returns the fields method, path, remote_addr and request_id (if the RequestIDHeader
+// is set) of r for an event or a request scoped logger:
+//
+//	log.Info().Fields(zlog.HTTPFields(r)).Msg("Request")
+//	l := log.With().Fields(zlog.HTTPFields(r)).Logger()
+func HTTPFields(r *http.Request) map[string]interface{} {
+	fields := map[string]interface{}{
+		"method":      r.Method,
+		"path":        r.URL.Path,
+		"remote_addr": r.RemoteAddr,
+	}
+	if id := r.Header.Get(RequestIDHeader); id != "" {
+		fields["request_id"] = id
+	}
+	return fields
+}
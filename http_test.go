@@ -0,0 +1,27 @@
+package zlog
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPFields(t *testing.T) {
+	r := httptest.NewRequest("POST", "/api/users?id=3", nil)
+	r.Header.Set("X-Request-Id", "4711")
+
+	l, buf := NewTest(Options{Format: FormatBW, TimeFormat: "none"})
+	l.Info().Fields(HTTPFields(r)).Msg("Request")
+	expected := "INF Request method=POST path=/api/users remote_addr=192.0.2.1:1234 request_id=4711\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	r.Header.Del("X-Request-Id")
+	scoped := l.With().Fields(HTTPFields(r)).Logger()
+	scoped.Info().Msg("Request")
+	expected = "INF Request method=POST path=/api/users remote_addr=192.0.2.1:1234\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
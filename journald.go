@@ -0,0 +1,140 @@
+//go:build linux
+// +build linux
+
+package zlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// Socket of the journald native protocol
+const journaldSocket = "/run/systemd/journal/socket"
+
+// Journald returns a logger sending events to systemd's journald with the native protocol.
+// Fields are sent as structured journal fields with uppercased names (e.g. "file" becomes
+// FILE), the level is mapped to PRIORITY. Events larger than the maximum datagram size
+// are not supported.
+func Journald(o Options) (zerolog.Logger, error) {
+	return newJournald(journaldSocket, o)
+}
+
+func newJournald(addr string, o Options) (zerolog.Logger, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return DisabledLogger, err
+	}
+	w := &journaldWriter{conn: conn, identifier: filepath.Base(os.Args[0])}
+	level, _ := o.level()
+	return zerolog.New(w).Level(zerologLevel(level)), nil
+}
+
+type journaldWriter struct {
+	conn       *net.UnixConn
+	identifier string
+}
+
+func (jw *journaldWriter) Write(p []byte) (int, error) {
+	return jw.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (jw *journaldWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	var evt map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if err := d.Decode(&evt); err != nil {
+		return 0, fmt.Errorf("cannot decode event: %s", err)
+	}
+
+	var buf bytes.Buffer
+	writeJournaldField(&buf, "PRIORITY", fmt.Sprint(journaldPriority(level)))
+	writeJournaldField(&buf, "SYSLOG_IDENTIFIER", jw.identifier)
+	if msg, ok := evt[zerolog.MessageFieldName]; ok {
+		writeJournaldField(&buf, "MESSAGE", fmt.Sprint(msg))
+	}
+	keys := make([]string, 0, len(evt))
+	for key := range evt {
+		switch key {
+		case zerolog.LevelFieldName, zerolog.TimestampFieldName, zerolog.MessageFieldName:
+		default:
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := journaldFieldName(key)
+		if name == "" {
+			continue
+		}
+		value, ok := evt[key].(string)
+		if !ok {
+			b, _ := json.Marshal(evt[key])
+			value = string(b)
+		}
+		writeJournaldField(&buf, name, value)
+	}
+	if _, err := jw.conn.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Syslog priority of the level, like the mapping in Syslog()
+func journaldPriority(level zerolog.Level) int {
+	switch level {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		return 7
+	case zerolog.InfoLevel:
+		return 6
+	case zerolog.WarnLevel:
+		return 4
+	case zerolog.ErrorLevel:
+		return 3
+	case zerolog.FatalLevel:
+		return 2 // crit, emerg is broadcast to all terminals
+	case zerolog.PanicLevel:
+		return 1
+	default:
+		return 5
+	}
+}
+
+// Journal field names are uppercase letters, digits and underscores and must not start with
+// an underscore (reserved for trusted fields)
+func journaldFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	return strings.TrimLeft(name, "_0123456789")
+}
+
+// Write a field in the native protocol, values with newlines use the binary format
+func writeJournaldField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
@@ -0,0 +1,62 @@
+//go:build linux
+// +build linux
+
+package zlog
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestJournald(t *testing.T) {
+	dir, err := os.MkdirTemp("", "zlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	addr := filepath.Join(dir, "journal.sock")
+	conn, err := net.ListenPacket("unixgram", addr)
+	if err != nil {
+		t.Skip("cannot listen on unix socket:", err)
+	}
+	defer conn.Close()
+
+	defer SetLevel(GetLevel())
+	SetLevel(0)
+	console := zlogOptions
+	l, err := newJournald(addr, Options{Level: 1, TimeFormat: "highres"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if GetLevel() != 0 || !reflect.DeepEqual(zlogOptions, console) {
+		t.Errorf("expected unchanged level and console options, got %d and %+v", GetLevel(), zlogOptions)
+	}
+	l.Warn().Str("file", "hosts").Str("_trace-id", "a\nb").Msg("Creating file")
+
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+	for _, expected := range []string{"PRIORITY=4\n", "MESSAGE=Creating file\n", "FILE=hosts\n", "TRACE_ID\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\n"} {
+		if !strings.Contains(msg, expected) {
+			t.Errorf("expected %q in %q", expected, msg)
+		}
+	}
+
+	// crit, emerg would be broadcast to all terminals
+	l.WithLevel(zerolog.FatalLevel).Msg("Giving up")
+	if n, _, err = conn.ReadFrom(buf); err != nil {
+		t.Fatal(err)
+	}
+	if msg := string(buf[:n]); !strings.Contains(msg, "PRIORITY=2\n") {
+		t.Errorf("expected crit priority in %q", msg)
+	}
+}
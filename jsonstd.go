@@ -0,0 +1,40 @@
+package zlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/rs/zerolog"
+)
+
+// jsonStdWriter writes JSON events with the zerolog default field names (StdFieldNames)
+// instead of the zlog field names used by the console logger
+type jsonStdWriter struct {
+	out io.Writer
+}
+
+func (w jsonStdWriter) Write(p []byte) (int, error) {
+	var evt map[string]json.RawMessage
+	if err := json.Unmarshal(p, &evt); err != nil {
+		return 0, fmt.Errorf("cannot decode event: %s", err)
+	}
+	for from, to := range map[string]string{
+		zerolog.TimestampFieldName: StdFieldNames.Time,
+		zerolog.LevelFieldName:     StdFieldNames.Level,
+		zerolog.MessageFieldName:   StdFieldNames.Message,
+	} {
+		if v, ok := evt[from]; ok && from != to {
+			delete(evt, from)
+			evt[to] = v
+		}
+	}
+	b, err := json.Marshal(evt)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := w.out.Write(append(b, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,35 @@
+package zlog
+
+import (
+	"io"
+
+	"github.com/rs/zerolog"
+)
+
+// LevelFilter returns a writer passing only events with level min or higher to w, e.g. to build
+// a zerolog.MultiLevelWriter with a level per destination. Events logged with Log() (NoLevel)
+// always pass like with zerolog's level check. Write() passes data without level through.
+func LevelFilter(w zerolog.LevelWriter, min zerolog.Level) zerolog.LevelWriter {
+	return levelFilterWriter{out: w, min: func() zerolog.Level { return min }}
+}
+
+// Writer dropping events below the level returned by min
+type levelFilterWriter struct {
+	out io.Writer
+	min func() zerolog.Level
+}
+
+// Write passes events without level information through
+func (w levelFilterWriter) Write(p []byte) (int, error) {
+	return w.out.Write(p)
+}
+
+func (w levelFilterWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < w.min() {
+		return len(p), nil
+	}
+	if lw, ok := w.out.(zerolog.LevelWriter); ok {
+		return lw.WriteLevel(level, p)
+	}
+	return w.out.Write(p)
+}
@@ -0,0 +1,50 @@
+package zlog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+type levelRecorder struct {
+	levels []zerolog.Level
+	buf    bytes.Buffer
+}
+
+func (r *levelRecorder) Write(p []byte) (int, error) { return r.buf.Write(p) }
+
+func (r *levelRecorder) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	r.levels = append(r.levels, level)
+	return r.buf.Write(p)
+}
+
+func TestLevelFilter(t *testing.T) {
+	levels := []zerolog.Level{zerolog.TraceLevel, zerolog.DebugLevel, zerolog.InfoLevel, zerolog.WarnLevel,
+		zerolog.ErrorLevel, zerolog.FatalLevel, zerolog.PanicLevel, zerolog.NoLevel}
+	for _, min := range levels[:len(levels)-1] {
+		var r levelRecorder
+		w := LevelFilter(&r, min)
+		for _, level := range levels {
+			if n, err := w.WriteLevel(level, []byte(level.String()+"\n")); err != nil || n != len(level.String())+1 {
+				t.Errorf("min %s: unexpected result %d %v for %s", min, n, err, level)
+			}
+		}
+		for _, level := range r.levels {
+			if level < min {
+				t.Errorf("min %s: unexpected level %s", min, level)
+			}
+		}
+		if expected := len(levels) - int(min-zerolog.TraceLevel); len(r.levels) != expected {
+			t.Errorf("min %s: expected %d events, got %v", min, expected, r.levels)
+		}
+		if r.levels[len(r.levels)-1] != zerolog.NoLevel {
+			t.Errorf("min %s: expected NoLevel to pass, got %v", min, r.levels)
+		}
+	}
+
+	var r levelRecorder
+	if _, err := LevelFilter(&r, zerolog.PanicLevel).Write([]byte("raw\n")); err != nil || r.buf.String() != "raw\n" {
+		t.Errorf("expected Write to pass through, got %q", r.buf.String())
+	}
+}
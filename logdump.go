@@ -0,0 +1,145 @@
+package zlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Logdump reads JSON logfiles as written by Tee(..., Options{Format: FormatJson}) and writes them
+// in console format to w. Options.Format selects the console format (FormatColor, FormatBW or
+// FormatUnicode) and Options.TimeFormat the timestamp format. With TimeFormat "s", "ms", "us" or "rel+clock"
+// the relative time is computed from the first timestamp in the logfile. Options.FieldNames are
+// the field names used in the logfile. Lines that cannot be parsed are copied to w unchanged. No
+// globals are changed, so Logdump can be used next to other loggers.
+func Logdump(r io.Reader, w io.Writer, o Options) error {
+	output := newConsoleWriter(o, colorsEnabled(o))
+	output.Out = w
+	output.FormatTimestamp = dumpTimestampFormatter(o.TimeFormat)
+	dump := dumpFieldNames(output, o.FieldNames)
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			if _, werr := dump.Write(line); werr != nil {
+				// not a JSON log line, copy verbatim
+				if !strings.HasSuffix(string(line), "\n") {
+					line = append(line, '\n')
+				}
+				if _, werr := w.Write(line); werr != nil {
+					return werr
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Returns a writer renaming the field names of the logfile to the zerolog field names used by the
+// console writer w
+func dumpFieldNames(w io.Writer, names FieldNames) io.Writer {
+	rename := map[string]string{}
+	for from, to := range map[string]string{
+		defaultString(names.Time, TimestampFieldName):  zerolog.TimestampFieldName,
+		defaultString(names.Level, LevelFieldName):     zerolog.LevelFieldName,
+		defaultString(names.Message, MessageFieldName): zerolog.MessageFieldName,
+	} {
+		if from != to {
+			rename[from] = to
+		}
+	}
+	if len(rename) == 0 {
+		return w
+	}
+	return renameWriter{out: w, rename: rename}
+}
+
+type renameWriter struct {
+	out    io.Writer
+	rename map[string]string
+}
+
+func (w renameWriter) Write(p []byte) (int, error) {
+	var evt map[string]json.RawMessage
+	if err := json.Unmarshal(p, &evt); err != nil {
+		return 0, fmt.Errorf("cannot decode event: %s", err)
+	}
+	for from, to := range w.rename {
+		if v, ok := evt[from]; ok {
+			delete(evt, from)
+			evt[to] = v
+		}
+	}
+	b, err := json.Marshal(evt)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := w.out.Write(b); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Parse the timestamp of a logfile event independent of the time format of the dump and the
+// globals: unix timestamps in seconds, milliseconds or microseconds are told apart by magnitude
+func parseDumpTimestamp(i interface{}) (time.Time, bool) {
+	if n, ok := i.(json.Number); ok {
+		if ts, err := n.Int64(); err == nil {
+			switch {
+			case ts > 1e14:
+				return time.Unix(0, ts*int64(time.Microsecond)), true
+			case ts > 1e11:
+				return time.Unix(0, ts*int64(time.Millisecond)), true
+			}
+			return time.Unix(ts, 0), true
+		}
+	}
+	return parseTimestamp(i, "")
+}
+
+// Timestamp formatter using the timestamp of the logged event instead of the current time
+func dumpTimestampFormatter(timeFormat string) zerolog.Formatter {
+	var first time.Time
+	return func(i interface{}) string {
+		t, ok := parseDumpTimestamp(i)
+		if !ok {
+			return fmt.Sprint(i)
+		}
+		switch timeFormat {
+		case "s", "ms", "us", "rel+clock":
+			if first.IsZero() {
+				first = t
+			}
+			switch timeFormat {
+			case "ms":
+				return fmt.Sprintf("[%06d]", t.Sub(first)/time.Millisecond)
+			case "us":
+				return fmt.Sprintf("[%09d]", t.Sub(first)/time.Microsecond)
+			case "rel+clock":
+				return fmt.Sprintf("[%04d] %s", t.Sub(first)/time.Second, t.Format("15:04:05"))
+			}
+			return fmt.Sprintf("[%04d]", t.Sub(first)/time.Second)
+		case "none":
+			return ""
+		case "", "default":
+			return t.Format("2006-01-02 15:04:05")
+		case "highres":
+			return t.Format("2006-01-02 15:04:05.000")
+		case "rfc3339", "rfc3339nano":
+			return t.Format(timeFieldFormat(timeFormat))
+		default:
+			return t.Format(timeFormat)
+		}
+	}
+}
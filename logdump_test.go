@@ -0,0 +1,65 @@
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestLogdump(t *testing.T) {
+	in := `{"_zl":"info","_zts":1663504199,"_zm":"ok"}
+not a log line
+{"_zl":"warn","n":3,"_zts":1663504203,"_zm":"This was an error"}
+`
+	var out bytes.Buffer
+	if err := Logdump(strings.NewReader(in), &out, Options{Format: FormatBW, TimeFormat: "s"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `[0000] INF ok
+not a log line
+[0004] WRN This was an error n=3
+`
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestLogdumpUnits(t *testing.T) {
+	// seconds, milliseconds and microseconds, 2s apart each
+	in := `{"_zl":"info","_zts":1663504199,"_zm":"s"}
+{"_zl":"info","_zts":1663504201000,"_zm":"ms"}
+{"_zl":"info","_zts":1663504203000000,"_zm":"us"}
+`
+	var out bytes.Buffer
+	if err := Logdump(strings.NewReader(in), &out, Options{Format: FormatBW, TimeFormat: "ms"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := "[000000] INF s\n[002000] INF ms\n[004000] INF us\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestLogdumpKeepsGlobals(t *testing.T) {
+	defer zconsoleWriter(Options{})
+	var console, file bytes.Buffer
+	New(Options{Format: FormatBW, TimeFormat: "none", Out: &console, FieldNames: StdFieldNames})
+
+	// logfile with the zlog field names, the console logger uses the zerolog defaults
+	in := `{"_zl":"info","_zts":1663504199,"_zm":"dumped"}` + "\n"
+	var out bytes.Buffer
+	if err := Logdump(strings.NewReader(in), &out, Options{Format: FormatUnicode, TimeFormat: "none"}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "🟢 dumped\n"; out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+
+	tee := TeeWriter(&file)
+	tee.Info().Msg("tee")
+	if console.String() != "INF tee\n" || zerolog.LevelFieldName != StdFieldNames.Level {
+		t.Errorf("expected unchanged console logger, got %q", console.String())
+	}
+}
@@ -0,0 +1,105 @@
+package zlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/rs/zerolog"
+)
+
+// logfmtWriter converts JSON events to logfmt lines like
+//
+//	_zl=info _zts=1644150896 _zm="Creating file" file=hosts
+type logfmtWriter struct {
+	out           io.Writer
+	maxMessageLen int // truncate longer messages, see Options.MaxMessageLen
+}
+
+// Returns the logfmt console output of New() with the options that apply to logfmt: HideFields,
+// MaxMessageLen and DurationUnit. The options for colors and the columns of the console formats
+// are ignored.
+func logfmtOutput(w io.Writer, o Options) io.Writer {
+	return hideFields(consoleDurations(logfmtWriter{out: w, maxMessageLen: o.MaxMessageLen}, o), o.HideFields)
+}
+
+func (w logfmtWriter) Write(p []byte) (int, error) {
+	var evt map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if err := d.Decode(&evt); err != nil {
+		return 0, fmt.Errorf("cannot decode event: %s", err)
+	}
+
+	keys := make([]string, 0, len(evt))
+	for key := range evt {
+		switch key {
+		case zerolog.LevelFieldName, zerolog.TimestampFieldName, zerolog.MessageFieldName:
+		default:
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	keys = append([]string{zerolog.LevelFieldName, zerolog.TimestampFieldName, zerolog.MessageFieldName}, keys...)
+
+	var buf bytes.Buffer
+	for _, key := range keys {
+		value, ok := evt[key]
+		if !ok {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		if s, ok := value.(string); ok && key == zerolog.MessageFieldName && w.maxMessageLen > 0 {
+			value = truncate(s, w.maxMessageLen)
+		}
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		buf.WriteString(logfmtValue(value))
+	}
+	buf.WriteByte('\n')
+	if _, err := buf.WriteTo(w.out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Format a decoded JSON value for logfmt, quoting it if necessary
+func logfmtValue(value interface{}) string {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return "null"
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return strconv.Quote(fmt.Sprint(v))
+		}
+		s = string(b)
+	}
+	if needsQuoting(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	return strings.IndexFunc(s, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r)
+	}) >= 0
+}
@@ -0,0 +1,46 @@
+package zlog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestLogfmt(t *testing.T) {
+	zconsoleWriter(Options{}) // set zlog field names
+	var buf bytes.Buffer
+	w := logfmtWriter{out: &buf}
+	for event, expected := range map[string]string{
+		`{"_zl":"info","_zm":"ok","file":"hosts","n":3,"ok":true}`:                     `_zl=info _zm=ok file=hosts n=3 ok=true`,
+		`{"_zl":"warn","_zts":1644150896,"_zm":"Creating file","empty":""}`:            `_zl=warn _zts=1644150896 _zm="Creating file" empty=""`,
+		`{"_zl":"error","_zm":"say \"hi\"","path":"a=b","nl":"line1\nline2"}`:          `_zl=error _zm="say \"hi\"" nl="line1\nline2" path="a=b"`,
+		`{"_zm":"nested","obj":{"a":1},"list":[1,2],"null":null,"unicode":"grün"}`:     `_zm=nested list=[1,2] null=null obj="{\"a\":1}" unicode=grün`,
+		`{"_zl":"debug","_zm":"backslash","path":"c:\\temp","tab":"a\tb","space":" "}`: `_zl=debug _zm=backslash path="c:\\temp" space=" " tab="a\tb"`,
+	} {
+		buf.Reset()
+		if _, err := w.Write([]byte(event)); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != expected+"\n" {
+			t.Errorf("expected %q, got %q", expected, buf.String())
+		}
+	}
+}
+
+func TestLogfmtOptions(t *testing.T) {
+	zconsoleWriter(Options{}) // set zlog field names
+	l, buf := NewTest(Options{
+		Format:        FormatLogfmt,
+		TimeFormat:    "none",
+		HideFields:    []string{"trace_id"},
+		MaxMessageLen: 8,
+		DurationUnit:  time.Millisecond,
+		BaseFields:    map[string]interface{}{"service": "api"},
+	})
+	l.Info().Str("trace_id", "abc").Dur("elapsed", 1500*time.Millisecond).Msg("Request finished")
+
+	expected := "_zl=info _zm=\"Request …\" elapsed=1.5s service=api\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
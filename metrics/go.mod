@@ -0,0 +1,8 @@
+module github.com/fpunkt/zlog/metrics
+
+go 1.16
+
+require (
+	github.com/prometheus/client_golang v1.11.1
+	github.com/rs/zerolog v1.25.0
+)
@@ -0,0 +1,28 @@
+// Package metrics provides a zerolog hook counting log messages with Prometheus. It is a
+// separate module to avoid the Prometheus dependency for users of zlog.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+// MetricsHook returns a hook incrementing the counter with the level label ("info", "warn", ...)
+// for every logged message. The counter must have exactly one label, e.g.
+//
+//	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+//		Name: "log_messages_total",
+//		Help: "Number of log messages by level",
+//	}, []string{"level"})
+//	log.Logger = zlog.New(zlog.Options{Hooks: []zerolog.Hook{metrics.MetricsHook(counter)}})
+func MetricsHook(counterVec *prometheus.CounterVec) zerolog.Hook {
+	return metricsHook{counterVec: counterVec}
+}
+
+type metricsHook struct {
+	counterVec *prometheus.CounterVec
+}
+
+func (h metricsHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	h.counterVec.WithLabelValues(level.String()).Inc()
+}
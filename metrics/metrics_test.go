@@ -0,0 +1,25 @@
+package metrics
+
+import (
+	"io"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+)
+
+func TestMetricsHook(t *testing.T) {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "log_messages_total"}, []string{"level"})
+	l := zerolog.New(io.Discard).Level(zerolog.InfoLevel).Hook(MetricsHook(counter))
+	l.Debug().Msg("below level")
+	l.Info().Msg("info")
+	l.Error().Msg("error 1")
+	l.Error().Msg("error 2")
+
+	for level, expected := range map[string]float64{"debug": 0, "info": 1, "error": 2} {
+		if n := testutil.ToFloat64(counter.WithLabelValues(level)); n != expected {
+			t.Errorf("expected %v %s messages, got %v", expected, level, n)
+		}
+	}
+}
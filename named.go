@@ -0,0 +1,83 @@
+package zlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// ComponentFieldName is the field name for the component of loggers created with Named()
+var ComponentFieldName = "component"
+
+// Named returns a child of the global logger for a component like "db". Messages carry the
+// component field, the console formats show the component as tag before the message:
+//
+//	INF [db] Connected host=localhost
+func Named(name string) zerolog.Logger {
+	return log.With().Str(ComponentFieldName, name).Logger()
+}
+
+// ResultFieldName is the field name for the result of Success()
+var ResultFieldName = "result"
+
+// Returns a writer for the console formats moving the component field into a "[component]" tag
+// in front of the message and showing the level of Success() messages as "ok"
+func consoleTags(w io.Writer, o Options, colors bool) io.Writer {
+	color := ""
+	if colors {
+		switch o.Format {
+		case FormatColor, FormatUnicode, FormatCompact, FormatColorLight:
+			color = paletteFor(o).component
+		}
+	}
+	return tagWriter{out: w, color: color}
+}
+
+type tagWriter struct {
+	out   io.Writer
+	color string
+}
+
+func (w tagWriter) Write(p []byte) (int, error) {
+	component := bytes.Contains(p, []byte(`"`+ComponentFieldName+`"`))
+	success := bytes.Contains(p, []byte(`"`+ResultFieldName+`":"ok"`))
+	if !component && !success {
+		return w.out.Write(p)
+	}
+	var evt map[string]json.RawMessage
+	if err := json.Unmarshal(p, &evt); err != nil {
+		return 0, fmt.Errorf("cannot decode event: %s", err)
+	}
+	if raw, ok := evt[ComponentFieldName]; ok {
+		var name, msg string
+		if err := json.Unmarshal(raw, &name); err != nil {
+			name = string(raw)
+		}
+		json.Unmarshal(evt[zerolog.MessageFieldName], &msg)
+		tag := "[" + name + "]"
+		if w.color != "" {
+			tag = w.color + tag + ResetColor
+		}
+		if msg != "" {
+			tag += " " + msg
+		}
+		delete(evt, ComponentFieldName)
+		evt[zerolog.MessageFieldName], _ = json.Marshal(tag)
+	}
+	if success && string(evt[ResultFieldName]) == `"ok"` && string(evt[zerolog.LevelFieldName]) == `"info"` {
+		delete(evt, ResultFieldName)
+		evt[zerolog.LevelFieldName] = json.RawMessage(`"ok"`)
+	}
+	b, err := json.Marshal(evt)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := w.out.Write(b); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,37 @@
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+)
+
+func TestNamed(t *testing.T) {
+	logger := log.Logger
+	defer func() { log.Logger = logger }()
+
+	var buf, js bytes.Buffer
+	log.Logger = New(Options{Format: FormatBW, TimeFormat: "none", Out: &buf})
+	db := Named("db")
+	db.Info().Str("host", "localhost").Msg("Connected")
+	if expected := "INF [db] Connected host=localhost\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	log.Logger = New(Options{Format: FormatColor, ForceColor: true, TimeFormat: "none", Out: &buf})
+	db = Named("db")
+	db.Info().Msg("Connected")
+	if !strings.Contains(buf.String(), Blue+"[db]"+ResetColor+" Connected") {
+		t.Errorf("expected colored tag in %q", buf.String())
+	}
+
+	log.Logger = TeeWriter(&js, Options{Format: FormatJson})
+	db = Named("db")
+	db.Info().Msg("Connected")
+	if !strings.Contains(js.String(), `"component":"db"`) || !strings.Contains(js.String(), `"_zm":"Connected"`) {
+		t.Errorf("expected component field in %q", js.String())
+	}
+}
@@ -0,0 +1,68 @@
+package zlog
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Opens the syslog for InitProduction(), replaced in tests
+var productionSyslog = openSyslog
+
+// InitProduction sets the global logger to the usual setup of services: console output as with
+// New(o), the JSON logfile Options.LogFile (Options.Overwrite, MaxSizeBytes, MaxBackups,
+// RotateDaily and Gzip apply) and the local syslog with the program name as tag. The fields and
+// hooks of o, e.g. BaseFields and Caller, apply to all outputs. Syslog is skipped on Windows and
+// Plan 9 or if the syslog daemon is not available. The level is applied once to all outputs.
+// Close the returned closer at the end of the program to close the logfile and the syslog
+// connection. If the logfile cannot be opened the error is returned and the global logger is not
+// changed.
+func InitProduction(o Options) (io.Closer, error) {
+	var closers multiCloser
+	var writers []io.Writer
+	if o.LogFile != "" {
+		fo := o
+		fo.Format = FormatJson
+		file, err := openTeeFile(o.LogFile, fo)
+		if err != nil {
+			return nil, err
+		}
+		writers = append(writers, file)
+		closers = append(closers, file)
+	}
+	sw, syslogErr := productionSyslog(filepath.Base(os.Args[0]), o)
+	if syslogErr == nil {
+		writers = append(writers, sw)
+		closers = append(closers, sw)
+	}
+	if !o.StartTime.IsZero() {
+		SetStartTime(o.StartTime)
+	}
+	writers = append([]io.Writer{consoleOutput(o)}, writers...)
+
+	level, _ := o.level()
+	l := setlevel(newLogger(zerolog.MultiLevelWriter(writers...), o, globalTimestamp{}), level)
+	loggerMu.Lock()
+	log.Logger = l
+	loggerMu.Unlock()
+	if syslogErr != nil {
+		l.Debug().Err(syslogErr).Msg("Not logging to syslog")
+	}
+	return closers, nil
+}
+
+// Closes all closers, returns the first error
+type multiCloser []io.Closer
+
+func (mc multiCloser) Close() error {
+	var first error
+	for _, c := range mc {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,82 @@
+package zlog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+type fakeSyslog struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (f *fakeSyslog) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestInitProduction(t *testing.T) {
+	defer func(l zerolog.Logger, level int) { log.Logger = setlevel(l, level) }(log.Logger, GetLevel())
+	defer func(open func(string, Options) (io.WriteCloser, error)) { productionSyslog = open }(productionSyslog)
+	var sys fakeSyslog
+	productionSyslog = func(tag string, o Options) (io.WriteCloser, error) { return &sys, nil }
+
+	var console bytes.Buffer
+	fname := filepath.Join(t.TempDir(), "service.log")
+	closer, err := InitProduction(Options{Format: FormatBW, TimeFormat: "none", Out: &console, LogFile: fname,
+		BaseFields: map[string]interface{}{"service": "api"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Debug().Msg("filtered")
+	log.Info().Str("file", "hosts").Msg("Creating file")
+	if err := closer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "INF Creating file file=hosts service=api\n"; console.String() != expected {
+		t.Errorf("expected console %q, got %q", expected, console.String())
+	}
+	b, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"_zm":"Creating file"`) || !strings.Contains(string(b), `"service":"api"`) ||
+		strings.Contains(string(b), "filtered") || strings.Contains(string(b), "_zts") {
+		t.Errorf("unexpected logfile %q", b)
+	}
+	if !strings.Contains(sys.String(), `"_zm":"Creating file"`) || strings.Contains(sys.String(), "filtered") || !sys.closed {
+		t.Errorf("unexpected syslog %q", sys.String())
+	}
+
+	// without syslog
+	productionSyslog = func(tag string, o Options) (io.WriteCloser, error) { return nil, errors.New("no syslog") }
+	console.Reset()
+	closer, err = InitProduction(Options{Format: FormatLogfmt, TimeFormat: "none", Out: &console, LogFile: fname})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Warn().Msg("still logging")
+	closer.Close()
+	if !strings.Contains(console.String(), `_zm="still logging"`) {
+		t.Errorf("expected logfmt console, got %q", console.String())
+	}
+
+	// logfile cannot be opened
+	l := log.Logger
+	if _, err := InitProduction(Options{LogFile: filepath.Join(fname, "service.log")}); err == nil {
+		t.Error("expected error for logfile in a file")
+	}
+	if !reflect.DeepEqual(log.Logger, l) {
+		t.Error("expected unchanged global logger")
+	}
+}
@@ -0,0 +1,70 @@
+package zlog
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// Ring keeps the last n log lines in memory, e.g. for a /debug/logs HTTP endpoint. Ring is an
+// io.Writer and can be used with any zerolog writer, each line written is stored separately.
+type Ring struct {
+	mu    sync.Mutex
+	lines []string
+	next  int // index of the next line to write
+	full  bool
+}
+
+// NewRing returns a ring buffer for the last n lines
+func NewRing(n int) *Ring {
+	if n < 1 {
+		n = 1
+	}
+	return &Ring{lines: make([]string, n)}
+}
+
+// RingWriter returns a ring buffer for the last n log lines and a logger writing to the console
+// and (in BW format) to the ring buffer
+func RingWriter(n int) (*Ring, zerolog.Logger) {
+	r := NewRing(n)
+	return r, teeLogger(teeFile{r, Options{Format: FormatBW}})
+}
+
+func (r *Ring) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		r.lines[r.next] = string(line)
+		r.next++
+		if r.next == len(r.lines) {
+			r.next = 0
+			r.full = true
+		}
+	}
+	return len(p), nil
+}
+
+// Lines returns the stored lines, oldest first
+func (r *Ring) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]string(nil), r.lines[:r.next]...)
+	}
+	return append(append([]string(nil), r.lines[r.next:]...), r.lines[:r.next]...)
+}
+
+// WriteTo writes the stored lines to w, oldest first
+func (r *Ring) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, line := range r.Lines() {
+		n, err := io.WriteString(w, line+"\n")
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
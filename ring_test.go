@@ -0,0 +1,22 @@
+package zlog
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	r := NewRing(3)
+	if len(r.Lines()) != 0 {
+		t.Errorf("expected empty ring, got %q", r.Lines())
+	}
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(r, "line %d\n", i)
+	}
+	var buf bytes.Buffer
+	r.WriteTo(&buf)
+	if buf.String() != "line 2\nline 3\nline 4\n" {
+		t.Errorf("unexpected lines %q", buf.String())
+	}
+}
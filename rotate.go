@@ -0,0 +1,168 @@
+package zlog
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// rotateWriter writes to a logfile and rotates it once it grows larger than maxSize.
+// Writes are serialized, so the writer can be shared by concurrent loggers.
+type rotateWriter struct {
+	mu         sync.Mutex
+	fname      string
+	fd         *os.File
+	size       int64
+	maxSize    int64
+	maxBackups int
+}
+
+func newRotateWriter(fname string, flag int, maxSize int64, maxBackups int) (*rotateWriter, error) {
+	if maxBackups < 1 {
+		maxBackups = 1
+	}
+	fd, err := os.OpenFile(fname, flag, 0666)
+	if err != nil {
+		return nil, err
+	}
+	var size int64
+	if fi, err := fd.Stat(); err == nil {
+		size = fi.Size()
+	}
+	return &rotateWriter{fname: fname, fd: fd, size: size, maxSize: maxSize, maxBackups: maxBackups}, nil
+}
+
+func (w *rotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var rotateErr error
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		// on errors the message is still written to the current logfile
+		rotateErr = w.rotate()
+	}
+	n, err := w.fd.Write(p)
+	w.size += int64(n)
+	if err == nil {
+		err = rotateErr
+	}
+	return n, err
+}
+
+// Shift fname.N-1 -> fname.N ... fname -> fname.1 and start a new logfile. If this fails the
+// logfile is reopened to append to it.
+func (w *rotateWriter) rotate() error {
+	if err := w.fd.Close(); err != nil {
+		return err
+	}
+	for i := w.maxBackups - 1; i > 0; i-- {
+		// older backups might not exist yet
+		_ = os.Rename(fmt.Sprintf("%s.%d", w.fname, i), fmt.Sprintf("%s.%d", w.fname, i+1))
+	}
+	if err := os.Rename(w.fname, w.fname+".1"); err != nil {
+		return reopen(&w.fd, w.fname, err)
+	}
+	fd, err := os.OpenFile(w.fname, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return reopen(&w.fd, w.fname, err)
+	}
+	w.fd = fd
+	w.size = 0
+	return nil
+}
+
+// Reopens the logfile fname to append to it after a failed rotation, returns err
+func reopen(fd **os.File, fname string, err error) error {
+	if f, oerr := os.OpenFile(fname, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666); oerr == nil {
+		*fd = f
+	}
+	return err
+}
+
+func (w *rotateWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.fd.Close()
+}
+
+// dailyWriter writes to a logfile and renames it to <fname>-2006-01-02 with the date of its
+// messages on the first write of a new day (local time). Writes are serialized.
+type dailyWriter struct {
+	mu    sync.Mutex
+	fname string
+	fd    *os.File
+	day   string
+}
+
+const dailyLayout = "2006-01-02"
+
+func newDailyWriter(fname string, flag int) (*dailyWriter, error) {
+	fd, err := os.OpenFile(fname, flag, 0666)
+	if err != nil {
+		return nil, err
+	}
+	day := now().Format(dailyLayout)
+	// an existing logfile written on an earlier day is rotated with the next write
+	if fi, err := fd.Stat(); err == nil && fi.Size() > 0 {
+		day = fi.ModTime().Format(dailyLayout)
+	}
+	return &dailyWriter{fname: fname, fd: fd, day: day}, nil
+}
+
+func (w *dailyWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var rotateErr error
+	if day := now().Format(dailyLayout); day != w.day {
+		// on errors the message is still written to the current logfile
+		rotateErr = w.rotate(day)
+	}
+	n, err := w.fd.Write(p)
+	if err == nil {
+		err = rotateErr
+	}
+	return n, err
+}
+
+// Rename fname to fname-<day of the logfile> and start a new logfile for day.
+// An existing fname-<day>, e.g. from a restart with a changed clock, is not overwritten,
+// the logfile is renamed to fname-<day>.1, fname-<day>.2 ... instead.
+func (w *dailyWriter) rotate(day string) error {
+	target, err := freeName(w.fname + "-" + w.day)
+	if err != nil {
+		return err
+	}
+	if err := w.fd.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(w.fname, target); err != nil {
+		return reopen(&w.fd, w.fname, err)
+	}
+	fd, err := os.OpenFile(w.fname, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return reopen(&w.fd, w.fname, err)
+	}
+	w.fd = fd
+	w.day = day
+	return nil
+}
+
+// Returns fname or the first fname.N that does not exist
+func freeName(fname string) (string, error) {
+	name := fname
+	for i := 1; ; i++ {
+		_, err := os.Lstat(name)
+		if os.IsNotExist(err) {
+			return name, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		name = fmt.Sprintf("%s.%d", fname, i)
+	}
+}
+
+func (w *dailyWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.fd.Close()
+}
@@ -0,0 +1,192 @@
+package zlog
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRotateSize(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "app.log")
+	w, err := newRotateWriter(fname, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// each line is 6 bytes, a second line in a logfile exceeds MaxSizeBytes
+	for _, line := range []string{"line1\n", "line2\n", "line3\n", "line4\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+
+	// line1 is dropped with MaxBackups 2
+	for name, expected := range map[string]string{
+		"app.log":   "line4\n",
+		"app.log.1": "line3\n",
+		"app.log.2": "line2\n",
+	} {
+		b, err := os.ReadFile(filepath.Join(filepath.Dir(fname), name))
+		if err != nil || string(b) != expected {
+			t.Errorf("%s: expected %q, got %q, %v", name, expected, b, err)
+		}
+	}
+	if _, err := os.Stat(fname + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected no third backup: %v", err)
+	}
+}
+
+func TestRotateSizeConcurrent(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "app.log")
+	w, err := newRotateWriter(fname, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 1100, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				w.Write([]byte("0123456789\n"))
+			}
+		}()
+	}
+	wg.Wait()
+	w.Close()
+
+	// no line is lost or torn, no logfile is larger than MaxSizeBytes
+	files, _ := filepath.Glob(fname + "*")
+	lines := 0
+	for _, name := range files {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b) > 1100 || strings.Count(string(b), "0123456789\n")*11 != len(b) {
+			t.Errorf("%s: unexpected content %q", name, b)
+		}
+		lines += len(b) / 11
+	}
+	if lines != 1000 {
+		t.Errorf("expected 1000 lines, got %d", lines)
+	}
+}
+
+func TestRotateSizeFailure(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "app.log")
+	// a directory cannot be replaced by the logfile
+	if err := os.MkdirAll(filepath.Join(fname+".1", "dir"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	w, err := newRotateWriter(fname, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("line1\n"))
+	if _, err := w.Write([]byte("line2\n")); err == nil {
+		t.Error("expected rotation error")
+	}
+	os.RemoveAll(fname + ".1")
+	if _, err := w.Write([]byte("line3\n")); err != nil {
+		t.Errorf("expected rotation after the error, got %v", err)
+	}
+	w.Close()
+
+	// no message is lost
+	for name, expected := range map[string]string{fname: "line3\n", fname + ".1": "line1\nline2\n"} {
+		b, err := os.ReadFile(name)
+		if err != nil || string(b) != expected {
+			t.Errorf("%s: expected %q, got %q, %v", name, expected, b, err)
+		}
+	}
+}
+
+func TestRotateDaily(t *testing.T) {
+	defer func(clock func() time.Time) { now = clock }(now)
+	clock := time.Date(2024, 3, 1, 23, 59, 0, 0, time.Local)
+	now = func() time.Time { return clock }
+
+	fname := filepath.Join(t.TempDir(), "app.log")
+	w, err := newDailyWriter(fname, os.O_CREATE|os.O_WRONLY|os.O_APPEND)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("before midnight\n"))
+	clock = clock.Add(2 * time.Minute)
+	w.Write([]byte("after midnight\n"))
+	// no write on March 3rd, the next write rotates lazily
+	clock = clock.Add(48 * time.Hour)
+	w.Write([]byte("two days later\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string]string{
+		"app.log-2024-03-01": "before midnight\n",
+		"app.log-2024-03-02": "after midnight\n",
+		"app.log":            "two days later\n",
+	} {
+		b, err := os.ReadFile(filepath.Join(filepath.Dir(fname), name))
+		if err != nil || string(b) != expected {
+			t.Errorf("%s: expected %q, got %q, %v", name, expected, b, err)
+		}
+	}
+}
+
+func TestTeeRotateDaily(t *testing.T) {
+	defer func(clock func() time.Time) { now = clock }(now)
+	clock := time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local)
+	now = func() time.Time { return clock }
+
+	fname := filepath.Join(t.TempDir(), "log.json")
+	l, closer := TeeWithCloser(fname, Options{Format: FormatJson, RotateDaily: true})
+	l = l.Output(closer.(io.Writer))
+	l.Info().Msg("first day")
+	clock = clock.Add(24 * time.Hour)
+	l.Info().Msg("second day")
+	closer.Close()
+
+	if _, err := os.Stat(fname + "-2024-03-01"); err != nil {
+		t.Errorf("expected rotated logfile: %v", err)
+	}
+}
+
+func TestRotateDailyExisting(t *testing.T) {
+	defer func(clock func() time.Time) { now = clock }(now)
+	clock := time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local)
+	now = func() time.Time { return clock }
+
+	fname := filepath.Join(t.TempDir(), "app.log")
+	for _, name := range []string{fname + "-2024-03-01", fname + "-2024-03-01.1"} {
+		if err := os.WriteFile(name, []byte(name+"\n"), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w, err := newDailyWriter(fname, os.O_CREATE|os.O_WRONLY|os.O_APPEND)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("first day\n"))
+	clock = clock.Add(24 * time.Hour)
+	if _, err := w.Write([]byte("second day\n")); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	for name, expected := range map[string]string{
+		fname + "-2024-03-01":   fname + "-2024-03-01\n",
+		fname + "-2024-03-01.1": fname + "-2024-03-01.1\n",
+		fname + "-2024-03-01.2": "first day\n",
+		fname:                   "second day\n",
+	} {
+		b, err := os.ReadFile(name)
+		if err != nil || string(b) != expected {
+			t.Errorf("%s: expected %q, got %q, %v", name, expected, b, err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+//go:build go1.21
+// +build go1.21
+
+package zlog
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/rs/zerolog"
+)
+
+// NewSlogHandler returns a handler for log/slog using the zlog console formatting. The level
+// threshold uses the zlog convention of Options.Level. Attributes of groups are prefixed with
+// the group name, e.g. "request.id". Like NewTest() the global options, e.g. the console of the
+// Tee loggers and SetTimeFormat(), are not affected.
+func NewSlogHandler(o Options) slog.Handler {
+	level, _ := o.level()
+	l := zerolog.New(localOutput(o)).Level(zerologLevel(level))
+	return &slogHandler{logger: l, timestamp: o.TimeFormat != "none", layout: timeFieldFormat(o.TimeFormat)}
+}
+
+type slogHandler struct {
+	logger    zerolog.Logger
+	prefix    string // group prefix for attribute keys
+	timestamp bool
+	layout    string // time field format of the timestamps, see timeFieldFormat()
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	zl := slogLevel(level)
+	return zl >= h.logger.GetLevel() && zl >= zerolog.GlobalLevel()
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	c := h.logger.With()
+	if h.timestamp && !r.Time.IsZero() {
+		c = c.Interface(zerolog.TimestampFieldName, timestampValue(r.Time, h.layout))
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		c = addSlogAttr(c, h.prefix, a)
+		return true
+	})
+	l := c.Logger()
+	l.WithLevel(slogLevel(r.Level)).Msg(r.Message)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := h.logger.With()
+	for _, a := range attrs {
+		c = addSlogAttr(c, h.prefix, a)
+	}
+	return &slogHandler{logger: c.Logger(), prefix: h.prefix, timestamp: h.timestamp, layout: h.layout}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{logger: h.logger, prefix: h.prefix + name + ".", timestamp: h.timestamp, layout: h.layout}
+}
+
+// Convert slog level to zerolog level
+func slogLevel(level slog.Level) zerolog.Level {
+	switch {
+	case level < slog.LevelDebug:
+		return zerolog.TraceLevel
+	case level < slog.LevelInfo:
+		return zerolog.DebugLevel
+	case level < slog.LevelWarn:
+		return zerolog.InfoLevel
+	case level < slog.LevelError:
+		return zerolog.WarnLevel
+	default:
+		return zerolog.ErrorLevel
+	}
+}
+
+func addSlogAttr(c zerolog.Context, prefix string, a slog.Attr) zerolog.Context {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return c
+	}
+	key := prefix + a.Key
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return c.Str(key, a.Value.String())
+	case slog.KindInt64:
+		return c.Int64(key, a.Value.Int64())
+	case slog.KindUint64:
+		return c.Uint64(key, a.Value.Uint64())
+	case slog.KindFloat64:
+		return c.Float64(key, a.Value.Float64())
+	case slog.KindBool:
+		return c.Bool(key, a.Value.Bool())
+	case slog.KindDuration:
+		return c.Dur(key, a.Value.Duration())
+	case slog.KindTime:
+		return c.Time(key, a.Value.Time())
+	case slog.KindGroup:
+		if a.Key != "" {
+			prefix = key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			c = addSlogAttr(c, prefix, ga)
+		}
+		return c
+	default:
+		if err, ok := a.Value.Any().(error); ok {
+			return c.AnErr(key, err)
+		}
+		return c.Interface(key, a.Value.Any())
+	}
+}
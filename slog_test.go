@@ -0,0 +1,52 @@
+//go:build go1.21
+// +build go1.21
+
+package zlog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewSlogHandler(Options{Format: FormatBW, TimeFormat: "none", Level: 1, Out: &buf}).(*slogHandler)
+
+	l := slog.New(h).With("service", "db")
+	l.Debug("connecting", "host", "localhost", "port", 5432)
+	l.WithGroup("request").Warn("slow", slog.Int("id", 7), slog.Bool("retry", true))
+	slog.New(h).Debug("more verbose", slog.Group("g", slog.String("a", "b")))
+	l.Log(context.Background(), slog.LevelDebug-4, "trace is filtered")
+
+	expected := `DBG connecting host=localhost port=5432 service=db
+WRN slow request.id=7 request.retry=true service=db
+DBG more verbose g.a=b
+`
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestSlogHandlerKeepsGlobals(t *testing.T) {
+	var console, file, buf bytes.Buffer
+	New(Options{Format: FormatBW, TimeFormat: "none", Out: &console})
+	h := NewSlogHandler(Options{Format: FormatUnicode, TimeFormat: "highres", Out: &buf})
+
+	ts := time.Date(2022, 2, 6, 12, 34, 56, 789000000, time.Local)
+	if err := h.Handle(context.Background(), slog.NewRecord(ts, slog.LevelInfo, "handled", 0)); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "2022-02-06 12:34:56.789 🟢 handled\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	// the Tee loggers still follow the console logger
+	tee := TeeWriter(&file)
+	tee.Info().Msg("tee")
+	if console.String() != "INF tee\n" {
+		t.Errorf("expected console of New(), got %q", console.String())
+	}
+}
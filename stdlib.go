@@ -0,0 +1,55 @@
+package zlog
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// StdlibWriter returns a writer for the standard library log package. Each line written is
+// logged with the global logger at the given level (zlog convention, 0 is info). Incomplete
+// lines are buffered until the newline is written or the writer is closed. Usage:
+//
+//	stdlog.SetFlags(0) // timestamps are added by zlog
+//	stdlog.SetOutput(zlog.StdlibWriter(0))
+func StdlibWriter(level int) io.WriteCloser {
+	return &stdlibWriter{level: level}
+}
+
+type stdlibWriter struct {
+	mu    sync.Mutex
+	level int
+	buf   []byte
+}
+
+func (w *stdlibWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Close logs any incomplete line
+func (w *stdlibWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.log(w.buf)
+		w.buf = nil
+	}
+	return nil
+}
+
+func (w *stdlibWriter) log(line []byte) {
+	log.WithLevel(zerologLevel(w.level)).Msg(string(bytes.TrimRight(line, "\r")))
+}
@@ -0,0 +1,38 @@
+package zlog
+
+import (
+	"bytes"
+	"fmt"
+	stdlog "log"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func TestStdlibWriter(t *testing.T) {
+	defer func(l zerolog.Logger) { log.Logger = l }(log.Logger)
+	var buf bytes.Buffer
+	log.Logger = zerolog.New(&buf)
+
+	w := StdlibWriter(-1)
+	l := stdlog.New(w, "", 0)
+	l.Print("first line")
+	fmt.Fprint(w, "partial ")
+	if strings.Contains(buf.String(), "partial") {
+		t.Errorf("unexpected partial line in %q", buf.String())
+	}
+	fmt.Fprint(w, "line\nunterminated")
+	w.Close()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %q", buf.String())
+	}
+	for i, msg := range []string{"first line", "partial line", "unterminated"} {
+		if !strings.Contains(lines[i], `"warn"`) || !strings.Contains(lines[i], `"`+msg+`"`) {
+			t.Errorf("expected warning %q in %q", msg, lines[i])
+		}
+	}
+}
@@ -0,0 +1,90 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package zlog
+
+import (
+	"bytes"
+	"io"
+	"log/syslog"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// Syslog returns a logger writing to the local syslog daemon with given tag. The levels are
+// mapped to syslog priorities, the messages are formatted like the BW console output without
+// timestamp (added by syslog).
+func Syslog(tag string, o Options) (zerolog.Logger, error) {
+	return newSyslog("", "", tag, o)
+}
+
+func newSyslog(network, raddr, tag string, o Options) (zerolog.Logger, error) {
+	sw, err := newSyslogWriter(network, raddr, tag, o)
+	if err != nil {
+		return DisabledLogger, err
+	}
+	level, _ := o.level()
+	return zerolog.New(sw).Level(zerologLevel(level)), nil
+}
+
+// Opens the local syslog for InitProduction()
+func openSyslog(tag string, o Options) (io.WriteCloser, error) {
+	return newSyslogWriter("", "", tag, o)
+}
+
+func newSyslogWriter(network, raddr, tag string, o Options) (*syslogWriter, error) {
+	w, err := syslog.Dial(network, raddr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, err
+	}
+	output := newConsoleWriter(o, false)
+	output.NoColor = true
+	output.FormatLevel = formatLevelBW
+	output.PartsExclude = []string{zerolog.TimestampFieldName}
+	return &syslogWriter{w: w, console: output}, nil
+}
+
+// syslogWriter formats events with the console writer and writes them with the syslog
+// priority of the level
+type syslogWriter struct {
+	w       *syslog.Writer
+	console zerolog.ConsoleWriter
+}
+
+func (sw *syslogWriter) Close() error { return sw.w.Close() }
+
+func (sw *syslogWriter) Write(p []byte) (int, error) {
+	return sw.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (sw *syslogWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	var buf bytes.Buffer
+	console := sw.console
+	console.Out = &buf
+	if _, err := console.Write(p); err != nil {
+		return 0, err
+	}
+	msg := strings.TrimSpace(buf.String())
+	var err error
+	switch level {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		err = sw.w.Debug(msg)
+	case zerolog.InfoLevel:
+		err = sw.w.Info(msg)
+	case zerolog.WarnLevel:
+		err = sw.w.Warning(msg)
+	case zerolog.ErrorLevel:
+		err = sw.w.Err(msg)
+	case zerolog.FatalLevel:
+		err = sw.w.Crit(msg) // not Emerg, which is broadcast to all terminals
+	case zerolog.PanicLevel:
+		err = sw.w.Alert(msg)
+	default:
+		err = sw.w.Notice(msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
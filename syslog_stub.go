@@ -0,0 +1,15 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package zlog
+
+import (
+	"errors"
+	"io"
+)
+
+// Syslog is not available on Windows and Plan 9, InitProduction() logs to the console and the
+// logfile only
+func openSyslog(tag string, o Options) (io.WriteCloser, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
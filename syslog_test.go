@@ -0,0 +1,62 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package zlog
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestSyslog(t *testing.T) {
+	dir, err := os.MkdirTemp("", "zlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	addr := filepath.Join(dir, "syslog.sock")
+	conn, err := net.ListenPacket("unixgram", addr)
+	if err != nil {
+		t.Skip("cannot listen on unix socket:", err)
+	}
+	defer conn.Close()
+
+	defer SetLevel(GetLevel())
+	SetLevel(0)
+	console := zlogOptions
+	l, err := newSyslog("unixgram", addr, "zlog", Options{Level: -1, Format: FormatUnicode})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if GetLevel() != 0 || !reflect.DeepEqual(zlogOptions, console) {
+		t.Errorf("expected unchanged level and console options, got %d and %+v", GetLevel(), zlogOptions)
+	}
+	l.Info().Msg("filtered")
+	l.Warn().Str("file", "hosts").Msg("Creating file")
+
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+	// LOG_USER (8) | LOG_WARNING (4)
+	if !strings.HasPrefix(msg, "<12>") || !strings.Contains(msg, "zlog[") || !strings.HasSuffix(msg, "WRN Creating file file=hosts\n") {
+		t.Errorf("unexpected syslog message %q", msg)
+	}
+
+	// LOG_USER (8) | LOG_CRIT (2), LOG_EMERG would be broadcast to all terminals
+	l.WithLevel(zerolog.FatalLevel).Msg("Giving up")
+	if n, _, err = conn.ReadFrom(buf); err != nil {
+		t.Fatal(err)
+	}
+	if msg := string(buf[:n]); !strings.HasPrefix(msg, "<10>") {
+		t.Errorf("expected crit priority, got %q", msg)
+	}
+}
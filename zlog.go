@@ -38,12 +38,16 @@ func main() {
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -52,9 +56,40 @@ import (
 )
 
 var (
+	// Default for loggers without Options.NoColor or Options.ForceColor, false if the
+	// terminal does not support colors
 	SupportColors = true
 )
 
+// Colors are disabled with Options.NoColor, if the terminal does not support them, if the output is
+// not a terminal (e.g. stderr redirected to a file) or if the NO_COLOR environment variable is set
+// (with any value), see https://no-color.org
+// Colors are enabled regardless of terminal support with Options.ForceColor or if the
+// FORCE_COLOR environment variable is set to a value other than "0". NO_COLOR takes
+// precedence over FORCE_COLOR.
+func colorsEnabled(o Options) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || o.NoColor {
+		return false
+	}
+	if fc := os.Getenv("FORCE_COLOR"); (fc != "" && fc != "0") || o.ForceColor {
+		return true
+	}
+	return SupportColors && isTerminal(o.output())
+}
+
+// Check whether w is a terminal (character device) and not a file, pipe or buffer
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
 // Init new console logger with given level. This will set the global zerolog.log.Logger.
 // Use zlog.New() if you need more flexibility
 func InitL(level int) { log.Logger = New(Options{Level: level}) }
@@ -62,6 +97,21 @@ func InitL(level int) { log.Logger = New(Options{Level: level}) }
 // Init new console logger with given level and high-resolution timestamp
 func InitLHR(level int) { log.Logger = New(Options{Level: level, TimeFormat: "highres"}) }
 
+// InitContainer sets the global logger to JSON on stdout for container environments like
+// Kubernetes: one JSON object per line with the StdFieldNames ("time", "level", "message"), RFC3339
+// timestamps and no colors. level follows the convention of Options.Level. The field names and the
+// time format are zerolog globals and also apply to other JSON loggers, the options of the console
+// logger (see Tee) are not changed.
+func InitContainer(level int) {
+	zerolog.TimeFieldFormat = time.RFC3339
+	zerolog.TimestampFieldName = StdFieldNames.Time
+	zerolog.LevelFieldName = StdFieldNames.Level
+	zerolog.MessageFieldName = StdFieldNames.Message
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	log.Logger = setlevel(zerolog.New(os.Stdout).With().Timestamp().Logger(), level)
+}
+
 // Init new console logger with level 0. This will set the global zerolog.log.Logger
 // Use zlog.New() if you need more flexibility
 func Init() { InitL(0) }
@@ -70,36 +120,260 @@ func Init() { InitL(0) }
 type Options struct {
 	// The level for the logger. NOTE: the convention here is 0 is info-level, positive numbers increase the verbosity,
 	// negative numbers decrease (e.g. -1 is warnings only, -2 errors only.). This allows
-	// convenient use of a verbose level commandline argument with pflag.CountVar()
+	// convenient use of a verbose level commandline argument with pflag.CountVar(). LevelSilent
+	// disables all output.
 	Level int
 
+	// The level for the logger by name, see ParseLevel(). Overrides Level if not empty.
+	LevelName string
+
+	// Output of the logger, default is os.Stderr. The Tee logfiles are not affected.
+	Out io.Writer `json:"-"`
+
+	// Start of the relative timestamps if not zero, see SetStartTime()
+	StartTime time.Time
+
 	// Timeformat for the output. This can be one of
 	//          "s": use relative time in seconds as timestamp, format is like [0004] (for 4 seconds)
+	//         "ms": use relative time in milliseconds, format is like [000412] (for 412ms)
+	//         "us": use relative time in microseconds, format is like [000412000] (for 412ms)
+	//  "rel+clock": relative time in seconds and wall clock, format is like [0042] 15:04:05
 	//  	 "none": supress time field in output
 	//    "default": Use "2006-01-02 15:04:05"
 	//           "": (empty string): same as default
 	//    "highres": Use "2006-01-02 15:04:05.000"
+	//    "rfc3339": Use time.RFC3339 like "2006-01-02T15:04:05+02:00", also for the JSON Tee output
+	// "rfc3339nano": Use time.RFC3339Nano
 	//        other: Any golang time format string
 	TimeFormat string
 
-	// This is the output format used with the Tee logger. Default is the same as the console logger
-	// but you might want to set this to Format: zlog.FormatJson
-	Format LogOutputFormat // used in Tee
+	// This is the output format of the console logger and the Tee logger. The logfiles of the Tee
+	// functions get FormatBW for all console formats including the zero value FormatColor, the same
+	// as without options. Use FormatConsole for the format and colors of the console logger or
+	// FormatJson for machine parsing.
+	Format LogOutputFormat
+
+	// Option for Tee logger: the level for the logfile if not nil, e.g. -1 for warnings only. The
+	// console keeps the level of the console logger. See Level for the level convention.
+	FileLevel *int
 
 	// Option for Tee logger, whether any existing logfile is overwritten. Default is to append to
 	// an existing logfile
 	Overwrite bool // used in Tee
+
+	// Always use colors for FormatColor and FormatUnicode, even if the terminal does not support
+	// them. The NO_COLOR environment variable still disables colors.
+	ForceColor bool
+
+	// Never use colors for this logger, regardless of terminal support, ForceColor and
+	// the package variable SupportColors.
+	NoColor bool
+
+	// Show field values in a subtle color (gray) with FormatColor, FormatColorLight and FormatUnicode.
+	// Error values are not affected.
+	ColorValues bool
+
+	// Show boolean field values green for true and red for false with FormatColor, FormatColorLight
+	// and FormatUnicode. The JSON formats are not affected.
+	ColorBools bool
+
+	// Use 24-bit colors (truecolor) instead of the 256 colors for levels and fields with FormatColor,
+	// FormatColorLight and FormatUnicode, see RGBColorize()
+	TrueColor bool
+
+	// Show the level of error, fatal and panic messages on a dark red background, see
+	// DarkRedBackground. Only used if colors are enabled.
+	HighlightErrors bool
+
+	// Pad the level to a display width of 3 columns (the width of "INF"), e.g. the emojis of
+	// FormatUnicode are only 2 columns wide. Longer levels like "nolevel" are not truncated.
+	AlignLevel bool
+
+	// Truncate messages longer than MaxMessageLen runes with an ellipsis "…" for the console
+	// formats and FormatLogfmt. The JSON formats always contain the full message. 0 means no limit.
+	MaxMessageLen int
+
+	// Unit of duration fields like log.Info().Dur("elapsed", d) in the JSON formats, see
+	// zerolog.DurationFieldUnit. If set the console formats and FormatLogfmt show the fields in
+	// DurationFieldNames like "123ms" or "1.5s".
+	DurationUnit time.Duration
+
+	// Separator written in front of each field in the console formats, e.g. "|" gives
+	// "INF Connected | host=localhost | port=22". Default is a space only.
+	FieldSeparator string
+
+	// Order of the columns in the console formats, any of "time", "level", "caller" and
+	// "message". Parts not given are not shown. Default is time, level, caller, message.
+	PartsOrder []string
+
+	// Add the field "seq" with a sequence number to every message, e.g. to detect lost or
+	// reordered lines. The numbers are unique for all loggers of the program and the same for
+	// the console and the logfiles of a Tee logger.
+	Sequence bool
+
+	// Colors for the levels with FormatColor and FormatColorLight, e.g. {"warn": "yellow",
+	// "debug": zlog.Blue}. The key is the level name ("trace", "debug", "info", "warn", "error",
+	// "fatal", "panic"), the value either a color name as used with NamedColorize() or an escape
+	// sequence.
+	LevelColors map[string]string
+
+	// Fields that are not shown in the console output, e.g. a trace_id which is only needed in
+	// the JSON logfile written with Tee
+	HideFields []string
+
+	// Add the caller (file:line) of the log message to the output, e.g. caller=main.go:68
+	Caller bool
+
+	// Only log every n-th trace and debug message to reduce the log volume, info and higher
+	// levels are always logged
+	SampleEvery int
+
+	// Sampler for all messages, overrides SampleEvery
+	Sampler zerolog.Sampler `json:"-"`
+
+	// Hooks called for every logged message, e.g. to count errors
+	Hooks []zerolog.Hook `json:"-"`
+
+	// Suppress identical messages within this window, see Dedup(). Zero disables deduplication.
+	Dedup time.Duration
+
+	// Use UTC instead of local time for the "default" and "highres" time formats
+	UTC bool
+
+	// Fields added to every message, e.g. service name and version. Fields are added sorted by key.
+	BaseFields map[string]interface{}
+
+	// Add the fields "host" (see os.Hostname()) and "pid" to every message
+	IncludeHostPID bool
+
+	// Option for Tee logger: rotate the logfile when it grows larger than MaxSizeBytes. The logfile
+	// is renamed to <fname>.1, older logfiles are shifted to <fname>.2 ... <fname>.<MaxBackups>.
+	// Zero disables rotation.
+	MaxSizeBytes int64 // used in Tee
+
+	// Number of rotated logfiles to keep, see MaxSizeBytes. Zero keeps one backup.
+	MaxBackups int // used in Tee
+
+	// Option for InitProduction(): the JSON logfile. Empty logs to the console and the syslog only.
+	LogFile string
+
+	// Option for Tee logger: rename the logfile to <fname>-2006-01-02 with the date of its messages
+	// with the first write after local midnight and start a new logfile. Overrides MaxSizeBytes,
+	// old logfiles are not removed. Not supported together with Gzip.
+	RotateDaily bool // used in Tee
+
+	// Option for Tee logger: add the caller and, for errors with a stack like errors.WithStack(),
+	// the stack (see ZMarshalStack) to the logfile, e.g. with FormatJson. The console and other
+	// logfiles don't show these fields.
+	ForensicJSON bool // used in Tee
+
+	// Option for Tee logger: compress the logfile with gzip, e.g. for "log.json.gz". Only
+	// supported for FormatJson and FormatJsonStd and not together with MaxSizeBytes. Each message
+	// is flushed, the logfile must be closed (see TeeWithCloser() and Close()) to write a complete
+	// gzip file.
+	Gzip bool // used in Tee
+
+	// JSON field names for timestamp, level and message. NOTE: zerolog only supports global
+	// field names, so these apply to all loggers. Empty names default to TimestampFieldName,
+	// LevelFieldName and MessageFieldName, use StdFieldNames for the zerolog defaults.
+	FieldNames FieldNames
 }
 
-type LogOutputFormat = int
+// FieldNames define the JSON field names for timestamp, level and message
+type FieldNames struct {
+	Time    string
+	Level   string
+	Message string
+}
+
+// Field names used by zlog in the JSON output
+const (
+	TimestampFieldName = "_zts"
+	LevelFieldName     = "_zl"
+	MessageFieldName   = "_zm"
+)
+
+// StdFieldNames are the zerolog default field names as expected by most JSON log consumers
+var StdFieldNames = FieldNames{Time: "time", Level: "level", Message: "message"}
+
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// LogOutputFormat selects the output format of a logger, see Options.Format
+type LogOutputFormat int
 
 const (
 	FormatColor LogOutputFormat = iota
 	FormatBW
 	FormatJson
 	FormatUnicode
+	FormatLogfmt     // key=value pairs, e.g. _zl=info _zm="Creating file" file=hosts, no colors or columns
+	FormatJsonStd    // JSON with the zerolog default field names time, level and message (Tee only)
+	FormatColorLight // colors for terminals with light background
+	FormatConsole    // the format of the console logger including colors (Tee only)
+	FormatCompact    // colored single character levels like "I" and "W" for dense logs
 )
 
+// Names of the builtin formats, e.g. for configuration through the environment
+var formatNames = map[string]LogOutputFormat{
+	"color":      FormatColor,
+	"bw":         FormatBW,
+	"json":       FormatJson,
+	"unicode":    FormatUnicode,
+	"logfmt":     FormatLogfmt,
+	"jsonstd":    FormatJsonStd,
+	"colorlight": FormatColorLight,
+	"console":    FormatConsole,
+	"compact":    FormatCompact,
+}
+
+// ParseFormat converts a format name like "color", "bw", "json" or "unicode" to the
+// LogOutputFormat. Names are case-insensitive and include formats registered with RegisterFormat.
+func ParseFormat(s string) (LogOutputFormat, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if format, ok := formatNames[name]; ok {
+		return format, nil
+	}
+	for format, custom := range customFormats {
+		if strings.ToLower(custom.name) == name {
+			return format, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log format %q", s)
+}
+
+// String returns the name of the format as accepted by ParseFormat
+func (f LogOutputFormat) String() string {
+	for name, format := range formatNames {
+		if format == f {
+			return name
+		}
+	}
+	if custom, ok := customFormats[f]; ok {
+		return custom.name
+	}
+	return fmt.Sprintf("LogOutputFormat(%d)", int(f))
+}
+
+// MarshalText implements encoding.TextMarshaler using the format name
+func (f LogOutputFormat) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler for format names accepted by ParseFormat
+func (f *LogOutputFormat) UnmarshalText(text []byte) error {
+	format, err := ParseFormat(string(text))
+	if err != nil {
+		return err
+	}
+	*f = format
+	return nil
+}
+
 const (
 	// see
 	// https://www.lihaoyi.com/post/BuildyourownCommandLinewithANSIescapecodes.html
@@ -129,8 +403,39 @@ const (
 	Magenta = _intro + "207m"
 	// Blue is the escape sequence to select Blue color
 	Blue = _intro + "33m"
+
+	// Colors for terminals with light background, see FormatColorLight
+
+	// LightGreen is the escape sequence to select a green readable on light background
+	LightGreen = _intro + "28m"
+	// LightOrange is the escape sequence to select an orange readable on light background
+	LightOrange = _intro + "166m"
+	// LightRed is the escape sequence to select a red readable on light background
+	LightRed = _intro + "160m"
+	// LightGray is the escape sequence to select a gray readable on light background
+	LightGray = _intro + "241m"
+	// LightCyan is the escape sequence to select a cyan readable on light background
+	LightCyan = _intro + "30m"
+	// LightBlue is the escape sequence to select a blue readable on light background
+	LightBlue = _intro + "25m"
+
+	_introBackground = "\033[48;5;"
+	// DarkRedBackground is the escape sequence to select a dark red background, see
+	// Options.HighlightErrors
+	DarkRedBackground = _introBackground + "52m"
 )
 
+// RGBColorize adds the control sequences for the 24-bit color r, g, b (truecolor) to text. Most
+// modern terminals support truecolor, use Colorize() with the 256 colors for compatibility.
+func RGBColorize(r, g, b uint8, text string) string {
+	return rgb(r, g, b) + text + ResetColor
+}
+
+// Returns the escape sequence to select the 24-bit foreground color r, g, b
+func rgb(r, g, b uint8) string {
+	return fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b)
+}
+
 // Prefix control sequence to string to colorize the output. Color-reset sequence is appended to the end of the string.
 func Colorize(colorcontrolsequence, text string) string {
 	return colorcontrolsequence + text + ResetColor
@@ -145,11 +450,43 @@ var colormap = map[string]string{
 	"cyan":    Cyan,
 	"magenta": Magenta,
 	"blue":    Blue,
+
+	"lightgreen":  LightGreen,
+	"lightorange": LightOrange,
+	"lightred":    LightRed,
+	"lightgray":   LightGray,
+	"lightcyan":   LightCyan,
+	"lightblue":   LightBlue,
 }
 
-// NamedColorize will add control sequences to for color output of the given string
+// NamedColorize will add control sequences to for color output of the given string. The text is
+// returned unchanged if the color name is unknown, see Palette() for the names.
 func NamedColorize(colorname, text string) string {
-	return colormap[colorname] + text + ResetColor
+	color, ok := colormap[colorname]
+	if !ok {
+		return text
+	}
+	return color + text + ResetColor
+}
+
+// ParseColor returns the escape sequence for a color name of Palette(), e.g. to report unknown
+// color names from a config file instead of the silent fallback of NamedColorize()
+func ParseColor(colorname string) (string, error) {
+	color, ok := colormap[colorname]
+	if !ok {
+		return "", fmt.Errorf("unknown color %q", colorname)
+	}
+	return color, nil
+}
+
+// Palette returns a copy of the color names used by NamedColorize() and Options.LevelColors with
+// their escape sequences, e.g. "green": Green
+func Palette() map[string]string {
+	p := make(map[string]string, len(colormap))
+	for name, color := range colormap {
+		p[name] = color
+	}
+	return p
 }
 
 func formatLevelUnicode(i interface{}) string {
@@ -169,6 +506,8 @@ func formatLevelUnicode(i interface{}) string {
 			return "❌"
 		case "panic":
 			return "❌"
+		case "ok":
+			return "✅"
 		case "log":
 			return "LOG"
 		case "":
@@ -202,6 +541,8 @@ func formatLevelBW(i interface{}) string {
 			return "FTL"
 		case "panic":
 			return "PNC"
+		case "ok":
+			return "OK"
 		case "log":
 			return "LOG"
 		case "":
@@ -216,38 +557,147 @@ func formatLevelBW(i interface{}) string {
 	return strings.ToUpper(fmt.Sprintf("%s", i))
 }
 
-func formatLevelColor(i interface{}) string {
-	if !SupportColors {
-		return formatLevelBW(i)
+// Level colors for FormatColor and FormatColorLight
+type palette struct {
+	trace, debug, info, warn, error string
+	field                           string // color for field names
+	value                           string // color for field values with Options.ColorValues
+	component                       string // color for the component tag, see Named()
+}
+
+var darkPalette = palette{trace: Gray, debug: Gray, info: Green, warn: Orange, error: Red, field: Cyan, value: Gray, component: Blue}
+
+var lightPalette = palette{trace: LightGray, debug: LightGray, info: LightGreen, warn: LightOrange, error: LightRed, field: LightCyan, value: LightGray, component: LightBlue}
+
+// 24-bit variants of darkPalette and lightPalette for Options.TrueColor
+var (
+	darkTrueColorPalette = palette{trace: rgb(148, 148, 148), debug: rgb(148, 148, 148), info: rgb(0, 255, 0), warn: rgb(255, 135, 0),
+		error: rgb(255, 0, 0), field: rgb(0, 215, 255), value: rgb(148, 148, 148), component: rgb(0, 135, 255)}
+	lightTrueColorPalette = palette{trace: rgb(98, 98, 98), debug: rgb(98, 98, 98), info: rgb(0, 135, 0), warn: rgb(215, 95, 0),
+		error: rgb(215, 0, 0), field: rgb(0, 135, 135), value: rgb(98, 98, 98), component: rgb(0, 95, 175)}
+)
+
+// Returns the palette for the format and Options.TrueColor
+func paletteFor(o Options) palette {
+	switch {
+	case o.Format == FormatColorLight && o.TrueColor:
+		return lightTrueColorPalette
+	case o.Format == FormatColorLight:
+		return lightPalette
+	case o.TrueColor:
+		return darkTrueColorPalette
 	}
-	if ll, ok := i.(string); ok {
-		switch ll {
-		case "trace":
-			return Gray + "TRC" + ResetColor
-		case "debug":
-			return Gray + "DBG" + ResetColor
-		case "info":
-			return Green + "INF" + ResetColor
-		case "warn":
-			return Orange + "WRN" + ResetColor
-		case "error":
-			return Red + "ERR" + ResetColor
-		case "fatal":
-			return Red + "FTL" + ResetColor
-		case "panic":
-			return Red + "PNC" + ResetColor
-		case "log":
-			return "LOG"
-		case "":
-			return "nolevel"
-		default:
-			fmt.Printf("ups, unexpeced level %q\n", ll)
+	return darkPalette
+}
+
+// Returns the level formatter for the palette. The colored levels are prepared upfront to
+// avoid mallocs.
+func (p palette) formatLevel() zerolog.Formatter {
+	trc := p.trace + "TRC" + ResetColor
+	dbg := p.debug + "DBG" + ResetColor
+	inf := p.info + "INF" + ResetColor
+	wrn := p.warn + "WRN" + ResetColor
+	err := p.error + "ERR" + ResetColor
+	ftl := p.error + "FTL" + ResetColor
+	pnc := p.error + "PNC" + ResetColor
+	suc := p.info + "OK" + ResetColor
+	return func(i interface{}) string {
+		if ll, ok := i.(string); ok {
+			switch ll {
+			case "trace":
+				return trc
+			case "debug":
+				return dbg
+			case "info":
+				return inf
+			case "warn":
+				return wrn
+			case "error":
+				return err
+			case "fatal":
+				return ftl
+			case "panic":
+				return pnc
+			case "ok":
+				return suc
+			case "log":
+				return "LOG"
+			case "":
+				return "nolevel"
+			default:
+				fmt.Printf("ups, unexpeced level %q\n", ll)
+			}
+		}
+		if i == nil {
+			return ""
 		}
+		return strings.ToUpper(fmt.Sprintf("%s", i))
 	}
-	if i == nil {
-		return ""
+}
+
+var formatLevelColor = darkPalette.formatLevel()
+
+// Single character levels and their colors (see NamedColorize()) for FormatCompact
+var (
+	compactLevels = map[string]string{"trace": "T", "debug": "D", "info": "I", "warn": "W", "error": "E", "fatal": "F", "panic": "P", "ok": "✓", "log": "L"}
+	compactColors = map[string]string{"trace": "gray", "debug": "gray", "info": "green", "warn": "orange", "error": "red", "fatal": "red", "panic": "red", "ok": "green"}
+)
+
+// Returns the level formatter for FormatCompact, other levels are formatted like FormatBW
+func formatLevelCompact(colors bool) zerolog.Formatter {
+	return func(i interface{}) string {
+		ll, _ := i.(string)
+		level, ok := compactLevels[ll]
+		if !ok {
+			return formatLevelBW(i)
+		}
+		if color, ok := compactColors[ll]; ok && colors {
+			return NamedColorize(color, level)
+		}
+		return level
+	}
+}
+
+var formatLevelColorLight = lightPalette.formatLevel()
+
+// Returns a level formatter using the colors from levelColors, either color names as used with
+// NamedColorize() or escape sequences. Levels not in levelColors use the default colors.
+func formatLevelCustomColor(levelColors map[string]string, defaultFormatter zerolog.Formatter) zerolog.Formatter {
+	colors := make(map[string]string, len(levelColors))
+	for level, color := range levelColors {
+		if c, ok := colormap[color]; ok {
+			color = c
+		}
+		colors[level] = color
+	}
+	return func(i interface{}) string {
+		if ll, ok := i.(string); ok {
+			if color, ok := colors[ll]; ok {
+				return color + formatLevelBW(i) + ResetColor
+			}
+		}
+		return defaultFormatter(i)
 	}
-	return strings.ToUpper(fmt.Sprintf("%s", i))
+}
+
+// Level formatters registered with RegisterFormat()
+var customFormats = map[LogOutputFormat]customFormat{}
+
+type customFormat struct {
+	name      string
+	formatter func(interface{}) string
+}
+
+// Ids for formats registered with RegisterFormat() start here to leave room for builtin formats
+const firstCustomFormat LogOutputFormat = 100
+
+// RegisterFormat registers a level formatter, e.g. for a bracketed "[INFO]" level, and returns
+// the format id to use with Options.Format. The formatter is called with the level name ("info",
+// "warn", ...). RegisterFormat is not thread-safe, call it at init time before creating loggers.
+func RegisterFormat(name string, fn func(interface{}) string) LogOutputFormat {
+	format := firstCustomFormat + LogOutputFormat(len(customFormats))
+	customFormats[format] = customFormat{name: name, formatter: fn}
+	return format
 }
 
 func getFormatter(format LogOutputFormat) func(interface{}) string {
@@ -258,17 +708,143 @@ func getFormatter(format LogOutputFormat) func(interface{}) string {
 		return formatLevelUnicode
 	case FormatColor:
 		return formatLevelColor
+	case FormatColorLight:
+		return formatLevelColorLight
+	case FormatCompact:
+		return formatLevelCompact(true)
 	default:
+		if cf, ok := customFormats[format]; ok {
+			return cf.formatter
+		}
 		return formatLevelBW
 	}
 }
 
-var zerologStartup = time.Now()
+// Returns a level formatter showing the error, fatal and panic levels on a dark red background
+func highlightErrors(formatLevel zerolog.Formatter) zerolog.Formatter {
+	return func(i interface{}) string {
+		switch i {
+		case "error", "fatal", "panic":
+			return DarkRedBackground + formatLevel(i) + ResetColor
+		}
+		return formatLevel(i)
+	}
+}
+
+// Returns s shortened to n runes and an ellipsis if s is longer than n runes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s // no more runes than bytes
+	}
+	runes := 0
+	for i := range s {
+		if runes == n {
+			return s[:i] + "…"
+		}
+		runes++
+	}
+	return s
+}
+
+// Display width of the levels with Options.AlignLevel
+const levelWidth = 3
+
+// Returns a level formatter padding the output of formatter to levelWidth columns
+func alignLevel(formatter zerolog.Formatter) zerolog.Formatter {
+	return func(i interface{}) string {
+		s := formatter(i)
+		if w := displayWidth(s); w < levelWidth {
+			s += strings.Repeat(" ", levelWidth-w)
+		}
+		return s
+	}
+}
+
+// Returns the number of terminal columns needed for s. Escape sequences have no width, emojis
+// and symbols (as used by FormatUnicode) take two columns.
+func displayWidth(s string) int {
+	w := 0
+	escape := false
+	for _, r := range s {
+		switch {
+		case escape:
+			escape = r == '[' || r < '@' || r > '~' // until the final byte of the escape sequence
+		case r == '\033':
+			escape = true
+		case r >= 0x2600 && r <= 0x27bf, r >= 0x1f300 && r <= 0x1faff:
+			w += 2
+		default:
+			w++
+		}
+	}
+	return w
+}
+
+// Start of the relative timestamps in unix nanoseconds, accessed atomically
+var zerologStartup = time.Now().UnixNano()
+
+// StartTime returns the start of the relative timestamps ("s", "ms", "us" and "rel+clock"), by
+// default the time the program was started
+func StartTime() time.Time { return time.Unix(0, atomic.LoadInt64(&zerologStartup)) }
+
+// SetStartTime sets the start of the relative timestamps, see also Options.StartTime
+func SetStartTime(t time.Time) { atomic.StoreInt64(&zerologStartup, t.UnixNano()) }
+
+// ResetStartTime restarts the relative timestamps at 0, e.g. after reinitializing a program
+func ResetStartTime() { SetStartTime(now()) }
+
+// Clock used for timestamps, replaced in tests
+var now = time.Now
+
+// Formatter for the time since startup in given unit
+func relativeTimestamp(format string, unit time.Duration, start func() time.Time) zerolog.Formatter {
+	return func(i interface{}) string { return fmt.Sprintf(format, now().Sub(start())/unit) }
+}
+
+// Timestamp layouts tried when parsing timestamps of logged events
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05.000",
+	"2006-01-02 15:04:05",
+	time.RFC3339Nano,
+}
+
+// Parse the timestamp field of a logged event written with the time field format layout (see
+// timeFieldFormat()). Timestamps are either unix time (in seconds, milliseconds or microseconds
+// depending on layout) or strings in layout or one of the timestampLayouts.
+func parseTimestamp(i interface{}, layout string) (time.Time, bool) {
+	switch ts := i.(type) {
+	case json.Number:
+		if n, err := ts.Int64(); err == nil {
+			switch layout {
+			case zerolog.TimeFormatUnixMs:
+				return time.Unix(0, n*int64(time.Millisecond)), true
+			case zerolog.TimeFormatUnixMicro:
+				return time.Unix(0, n*int64(time.Microsecond)), true
+			}
+			return time.Unix(n, 0), true
+		}
+		if f, err := ts.Float64(); err == nil {
+			return time.Unix(0, int64(f*float64(time.Second))), true
+		}
+	case string:
+		layouts := timestampLayouts
+		if layout != "" {
+			layouts = append([]string{layout}, layouts...)
+		}
+		for _, layout := range layouts {
+			if t, err := time.ParseInLocation(layout, ts, time.Local); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
 
 // Defines how many stack frames are dropped from the stack traces.
 var ZlogDropStack = 8
 
-// Returns nil or a short stack dump like "main.go:68 | proc.go:225 | asm_amd64.s:1371"
+// Returns nil or a short stack dump like "main.go:68 | proc.go:225 | asm_amd64.s:1371".
+// The last ZlogDropStack frames are dropped, nil is returned if no frames are left.
 func ZMarshalStack(err error) interface{} {
 	type stackTracer interface {
 		StackTrace() errors.StackTrace
@@ -278,15 +854,12 @@ func ZMarshalStack(err error) interface{} {
 		return nil
 	}
 	st := sterr.StackTrace()
-	nlevels := len(st)
-	if nlevels > ZlogDropStack {
-		nlevels -= ZlogDropStack
+	if len(st) <= ZlogDropStack {
+		return nil
 	}
+	st = st[:len(st)-ZlogDropStack]
 	b := []byte{}
 	for i, frame := range st {
-		if i > nlevels {
-			return string(b)
-		}
 		pc := uintptr(frame) - 1
 		fn := runtime.FuncForPC(pc)
 		if fn == nil {
@@ -304,13 +877,107 @@ func ZMarshalStack(err error) interface{} {
 	return string(b)
 }
 
+// Returns the console writer for o, sets the zerolog time format and field names and remembers o
+// for the Tee loggers
 func zconsoleWriter(o Options) zerolog.ConsoleWriter {
 	zlogOptions = o
+	zerolog.ErrorStackMarshaler = ZMarshalStack
+	zerolog.TimeFieldFormat = timeFieldFormat(o.TimeFormat)
+	if o.DurationUnit > 0 {
+		zerolog.DurationFieldUnit = o.DurationUnit
+		zerolog.DurationFieldInteger = false
+	}
+	zerolog.TimestampFieldName = defaultString(o.FieldNames.Time, TimestampFieldName)
+	zerolog.LevelFieldName = defaultString(o.FieldNames.Level, LevelFieldName)
+	zerolog.MessageFieldName = defaultString(o.FieldNames.Message, MessageFieldName)
+	return newConsoleWriter(o, colorsEnabled(o))
+}
+
+// timestampSwitch is the timestamp formatter of a console writer that can be replaced while the
+// writer is in use, see SetTimeFormat()
+type timestampSwitch struct {
+	formatter atomic.Value // zerolog.Formatter
+}
+
+func newTimestampSwitch(formatter zerolog.Formatter) *timestampSwitch {
+	ts := &timestampSwitch{}
+	ts.formatter.Store(formatter)
+	return ts
+}
+
+func (ts *timestampSwitch) format(i interface{}) string {
+	return ts.formatter.Load().(zerolog.Formatter)(i)
+}
+
+// Timestamp formatter of the console logger last created with New(), replaced by SetTimeFormat()
+var consoleTimestamp atomic.Value // *timestampSwitch
+
+// SetTimeFormat changes the time format (see Options.TimeFormat) of the console logger last
+// created with New() or the Init functions and of the console of its Tee loggers in place, e.g.
+// from relative "s" to wall clock "default" when a long operation begins. Level, fields and hooks
+// of the loggers are kept, other loggers are not changed. NOTE: the timestamp in the JSON output
+// is formatted with the global zerolog.TimeFieldFormat, which is changed too. Like New(), don't
+// call it while other goroutines are logging.
+func SetTimeFormat(tf string) {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	zlogOptions.TimeFormat = tf
+	zerolog.TimeFieldFormat = timeFieldFormat(tf)
+	if ts, ok := consoleTimestamp.Load().(*timestampSwitch); ok {
+		ts.formatter.Store(timestampFormatter(zlogOptions))
+	}
+}
+
+// Returns the zerolog time field format for Options.TimeFormat
+func timeFieldFormat(timeFormat string) string {
+	switch timeFormat {
+	case "s", "rel+clock", "", "default", "none":
+		return zerolog.TimeFormatUnix
+	case "ms":
+		return zerolog.TimeFormatUnixMs
+	case "us":
+		return zerolog.TimeFormatUnixMicro
+	case "highres":
+		return "2006-01-02 15:04:05.000"
+	case "rfc3339":
+		return time.RFC3339
+	case "rfc3339nano":
+		return time.RFC3339Nano
+	}
+	return timeFormat
+}
+
+// Returns the console formatter of the timestamp for Options.TimeFormat
+func timestampFormatter(o Options) zerolog.Formatter {
 	var timestampFormat zerolog.Formatter
+	start := StartTime
+	if !o.StartTime.IsZero() {
+		start = func() time.Time { return o.StartTime }
+	}
+	layout := timeFieldFormat(o.TimeFormat)
+	// use the timestamp of the event, not the time the event is formatted
+	clock := func(i interface{}) time.Time {
+		t, ok := parseTimestamp(i, layout)
+		if !ok {
+			t = now()
+		}
+		if o.UTC {
+			return t.UTC()
+		}
+		return t
+	}
 	switch o.TimeFormat {
 	case "s":
-		timestampFormat = func(i interface{}) string { return fmt.Sprintf("[%04d]", time.Since(zerologStartup)/time.Second) }
-		o.TimeFormat = zerolog.TimeFormatUnix
+		timestampFormat = relativeTimestamp("[%04d]", time.Second, start)
+	case "rel+clock":
+		relative := relativeTimestamp("[%04d]", time.Second, start)
+		timestampFormat = func(i interface{}) string {
+			return relative(i) + " " + clock(i).Format("15:04:05")
+		}
+	case "ms":
+		timestampFormat = relativeTimestamp("[%06d]", time.Millisecond, start)
+	case "us":
+		timestampFormat = relativeTimestamp("[%09d]", time.Microsecond, start)
 	case "none":
 		timestampFormat = func(i interface{}) string { return "" }
 	case "", "default":
@@ -318,39 +985,88 @@ func zconsoleWriter(o Options) zerolog.ConsoleWriter {
 		// for some strange reason some programs dump the timestamp with +2h offset
 		// when the standard formatter is used.
 		// (First seen when using the NATS library, but not clear whether there is a correlation)
+		// The standard formatter parses timestamps without zone as UTC, parseTimestamp() uses local time.
 		timestampFormat = func(i interface{}) string {
-			//fmt.Printf("i = %t / %v\n", i, i) // unix time in seconds (probably), a json.number
-			return time.Now().Format("2006-01-02 15:04:05")
+			return clock(i).Format("2006-01-02 15:04:05")
 		}
 	case "highres":
-		o.TimeFormat = "2006-01-02 15:04:05.000"
 		timestampFormat = func(i interface{}) string {
-			return time.Now().Format("2006-01-02 15:04:05.000")
+			return clock(i).Format("2006-01-02 15:04:05.000")
+		}
+	case "rfc3339", "rfc3339nano":
+		timestampFormat = func(i interface{}) string {
+			return clock(i).Format(layout)
 		}
 	default:
 		//panic(fmt.Sprintf("Bad timeformat %q", o.TimeFormat))
 		// provided by user as regular golang timeformat template
+		timestampFormat = func(i interface{}) string {
+			return clock(i).Format(o.TimeFormat)
+		}
 	}
-	zerolog.ErrorStackMarshaler = ZMarshalStack
-	zerolog.TimeFieldFormat = o.TimeFormat
-	zerolog.TimestampFieldName = "_zts"
-	zerolog.LevelFieldName = "_zl"
-	zerolog.MessageFieldName = "_zm"
-
-	//o.PartsOrder = nil
+	return timestampFormat
+}
 
-	output := zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: o.TimeFormat}
+// Returns the console writer for o with or without colors. Unlike zconsoleWriter() no package
+// or zerolog globals are changed, so this is safe to use while other goroutines are logging.
+func newConsoleWriter(o Options, colors bool) zerolog.ConsoleWriter {
+	timestampFormat := timestampFormatter(o)
+	// zerolog's ConsoleWriter sorts the fields by name with the error field first, so the output
+	// does not depend on the order the fields are added.
+	output := zerolog.ConsoleWriter{Out: o.output(), TimeFormat: timeFieldFormat(o.TimeFormat)}
+	output.PartsOrder, _ = partsOrder(o.PartsOrder)
 	output.FormatLevel = getFormatter(o.Format)
 
+	colorFormat := o.Format == FormatColor || o.Format == FormatColorLight
+	if colorFormat && o.TrueColor {
+		output.FormatLevel = paletteFor(o).formatLevel()
+	}
+	if !colors {
+		output.NoColor = true
+		if colorFormat {
+			output.FormatLevel = formatLevelBW
+		} else if o.Format == FormatCompact {
+			output.FormatLevel = formatLevelCompact(false)
+		}
+	} else if colorFormat && len(o.LevelColors) > 0 {
+		output.FormatLevel = formatLevelCustomColor(o.LevelColors, output.FormatLevel)
+	}
+	if o.AlignLevel {
+		output.FormatLevel = alignLevel(output.FormatLevel)
+	}
+	if o.HighlightErrors && colors {
+		output.FormatLevel = highlightErrors(output.FormatLevel)
+	}
+	if o.MaxMessageLen > 0 {
+		output.FormatMessage = func(i interface{}) string {
+			if i == nil {
+				return ""
+			}
+			return truncate(fmt.Sprint(i), o.MaxMessageLen)
+		}
+	}
+
 	// patch colors to be more readable
-	if (o.Format == FormatColor || o.Format == FormatUnicode) && SupportColors {
+	var changeColors *palette
+	if (colorFormat || o.Format == FormatUnicode || o.Format == FormatCompact) && colors {
+		p := paletteFor(o)
+		changeColors = &p
 		output.FormatFieldName = func(i interface{}) string {
-			return Cyan + fmt.Sprint(i) + "=" + ResetColor
+			return p.field + fmt.Sprint(i) + "=" + ResetColor
+		}
+		if o.ColorValues {
+			output.FormatFieldValue = func(i interface{}) string {
+				return p.value + fmt.Sprintf("%s", i) + ResetColor
+			}
 		}
 
 		// use red color for error messages
+		red := Red
+		if o.TrueColor {
+			red = p.error
+		}
 		output.FormatErrFieldName = func(i interface{}) string {
-			return Red + "error=" + ResetColor
+			return red + "error=" + ResetColor
 		}
 		output.FormatErrFieldValue = func(i interface{}) string {
 			//return fmt.Sprint(i) + ResetColor
@@ -361,24 +1077,314 @@ func zconsoleWriter(o Options) zerolog.ConsoleWriter {
 		output.FormatErrFieldName = func(i interface{}) string { return "error=" }
 		output.FormatErrFieldValue = func(i interface{}) string { return fmt.Sprint(i) }
 	}
-
-	if timestampFormat != nil {
-		output.FormatTimestamp = timestampFormat
+	fieldValue := output.FormatFieldValue
+	if fieldValue == nil {
+		fieldValue = func(i interface{}) string { return fmt.Sprintf("%s", i) }
+	}
+	output.FormatFieldValue = func(i interface{}) string {
+		if s, ok := formatChange(i, changeColors); ok {
+			return s
+		}
+		if o.ColorBools && changeColors != nil {
+			if s, ok := formatBool(i, changeColors); ok {
+				return s
+			}
+		}
+		return fieldValue(i)
+	}
+	if o.FieldSeparator != "" {
+		prefix := o.FieldSeparator + " "
+		fieldName, errFieldName := output.FormatFieldName, output.FormatErrFieldName
+		output.FormatFieldName = func(i interface{}) string { return prefix + fieldName(i) }
+		output.FormatErrFieldName = func(i interface{}) string { return prefix + errFieldName(i) }
 	}
+
+	output.FormatTimestamp = timestampFormat
 	return output
 }
 
+// Format boolean field values green for true and red for false. The ConsoleWriter passes
+// booleans as JSON encoded []byte, string fields like "true" are passed as string.
+func formatBool(i interface{}, p *palette) (string, bool) {
+	b, ok := i.([]byte)
+	if !ok {
+		return "", false
+	}
+	switch string(b) {
+	case "true":
+		return p.info + "true" + ResetColor, true
+	case "false":
+		return p.error + "false" + ResetColor, true
+	}
+	return "", false
+}
+
+// Returns the writer of the console logger, dropping the fields in Options.HideFields. The
+// timestamp format can be changed with SetTimeFormat(). FormatLogfmt gives the logfmt writer.
+func consoleOutput(o Options) io.Writer {
+	output := zconsoleWriter(o)
+	if o.Format == FormatLogfmt {
+		return logfmtOutput(o.output(), o)
+	}
+	ts := newTimestampSwitch(output.FormatTimestamp)
+	output.FormatTimestamp = ts.format
+	consoleTimestamp.Store(ts)
+	return consoleWrappers(output, o, colorsEnabled(o))
+}
+
+// Returns the console writer for the Tee loggers with the options of the console logger. The
+// globals are left alone, the console logger may be in use by other goroutines.
+func teeConsole() io.Writer {
+	colors := colorsEnabled(zlogOptions)
+	output := newConsoleWriter(zlogOptions, colors)
+	if ts, ok := consoleTimestamp.Load().(*timestampSwitch); ok {
+		output.FormatTimestamp = ts.format // follow SetTimeFormat()
+	}
+	return consoleWrappers(output, zlogOptions, colors)
+}
+
+// Returns the writers rewriting the events for the console writer w: component tags, durations and
+// hidden fields
+func consoleWrappers(w io.Writer, o Options, colors bool) io.Writer {
+	return hideFields(consoleDurations(consoleTags(w, o, colors), o), o.HideFields)
+}
+
+// Returns a writer that drops the given fields from JSON events before writing them to w
+func hideFields(w io.Writer, fields []string) io.Writer {
+	if len(fields) == 0 {
+		return w
+	}
+	return hideFieldsWriter{out: w, fields: fields}
+}
+
+type hideFieldsWriter struct {
+	out    io.Writer
+	fields []string
+}
+
+func (w hideFieldsWriter) Write(p []byte) (int, error) {
+	var evt map[string]json.RawMessage
+	if err := json.Unmarshal(p, &evt); err != nil {
+		return 0, fmt.Errorf("cannot decode event: %s", err)
+	}
+	for _, field := range w.fields {
+		delete(evt, field)
+	}
+	b, err := json.Marshal(evt)
+	if err != nil {
+		return 0, err
+	}
+	// keep the newline of the event for JSON logfiles
+	if _, err := w.out.Write(append(b, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Returns the short caller like "main.go:68", see Options.Caller
+func zMarshalCaller(file string, line int) string {
+	return path.Base(file) + ":" + strconv.Itoa(line)
+}
+
+// Hostname for Options.IncludeHostPID, resolved once
+var hostname, _ = os.Hostname()
+
+// SequenceFieldName is the field name for the sequence numbers of Options.Sequence
+var SequenceFieldName = "seq"
+
+// Last sequence number of Options.Sequence, accessed atomically
+var sequence uint64
+
+type sequenceHook struct{}
+
+func (sequenceHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	e.Uint64(SequenceFieldName, atomic.AddUint64(&sequence, 1))
+}
+
+// Exit function of Error.Fatal(), see SetExitFunc()
+var exitFunc = os.Exit
+
+// SetExitFunc sets the function called by Error.Fatal() with exit code 1, nil restores the default
+// os.Exit. Tests can use this to intercept fatal errors. Note that zerolog's Logger.Fatal() always
+// calls os.Exit. Don't call it while other goroutines are logging.
+func SetExitFunc(f func(int)) {
+	if f == nil {
+		f = os.Exit
+	}
+	exitFunc = f
+}
+
 // Store last options here for tlog (needs to create new loggers with Tee and others)
 var zlogOptions Options
 
 // Returns a new zerolog console logger instance with given options
 func New(o Options) zerolog.Logger {
-	output := zconsoleWriter(o)
+	if !o.StartTime.IsZero() {
+		SetStartTime(o.StartTime)
+	}
+	level, _ := o.level()
+	return setlevel(newLogger(consoleOutput(o), o, globalTimestamp{}), level)
+}
+
+// Returns a logger for output with the timestamp added by the hook ts and the fields, level,
+// sampler and hooks of o. Unlike New() no package or zerolog globals are changed.
+func newLogger(output io.Writer, o Options, ts zerolog.Hook) zerolog.Logger {
 	zlog := zerolog.New(output).With()
+	if len(o.BaseFields) > 0 {
+		zlog = zlog.Fields(o.BaseFields) // zerolog sorts the keys
+	}
+	if o.IncludeHostPID {
+		zlog = zlog.Str("host", hostname).Int("pid", os.Getpid())
+	}
+	level, err := o.level()
+	l := zlog.Logger().Level(zerologLevel(level))
 	if o.TimeFormat != "none" {
-		zlog = zlog.Timestamp()
+		l = l.Hook(ts)
+	}
+	if o.Caller {
+		l = l.Hook(callerHook{})
+	}
+	if sampler := o.sampler(); sampler != nil {
+		l = l.Sample(sampler)
+	}
+	for _, hook := range o.Hooks {
+		l = l.Hook(hook)
+	}
+	if o.Sequence {
+		l = l.Hook(sequenceHook{})
+	}
+	if o.Dedup > 0 {
+		l = l.Hook(Dedup(o.Dedup, l))
+	}
+	if err != nil {
+		l.Warn().Err(err).Msg("Ignoring LevelName option")
+	}
+	if _, err := partsOrder(o.PartsOrder); err != nil {
+		l.Warn().Err(err).Msg("Ignoring PartsOrder option")
+	}
+	return l
+}
+
+// NewTest returns a logger like New() writing to the returned buffer, e.g. to check the log
+// output in tests. Options.Out is ignored. Colors are only used with Options.ForceColor. Unlike
+// New() the global options, e.g. the level of GetLevel() and the console of the Tee loggers, are
+// not changed.
+func NewTest(o Options) (zerolog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	o.Out = &buf
+	return newLocalLogger(o), &buf
+}
+
+// Returns a logger like New() writing to o.Out without changing globals. The timestamps are
+// written in the time format of o, not in the global zerolog.TimeFieldFormat.
+func newLocalLogger(o Options) zerolog.Logger {
+	return newLogger(localOutput(o), o, timestampHook(timeFieldFormat(o.TimeFormat)))
+}
+
+// Returns the console or logfmt writer for o like New() without changing globals
+func localOutput(o Options) io.Writer {
+	if o.Format == FormatLogfmt {
+		return logfmtOutput(o.output(), o)
+	}
+	colors := colorsEnabled(o)
+	return consoleWrappers(newConsoleWriter(o, colors), o, colors)
+}
+
+// globalTimestamp adds the timestamp in the global zerolog.TimeFieldFormat like zerolog's
+// Context.Timestamp(), so SetTimeFormat() applies
+type globalTimestamp struct{}
+
+func (globalTimestamp) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	e.Timestamp()
+}
+
+// timestampHook adds the timestamp in the given time field format (see timeFieldFormat()) instead
+// of the global zerolog.TimeFieldFormat, e.g. with milliseconds for the "highres" time format
+type timestampHook string
+
+func (layout timestampHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	e.Interface(zerolog.TimestampFieldName, timestampValue(zerolog.TimestampFunc(), string(layout)))
+}
+
+// Returns t in the time field format layout like zerolog's Event.Time() with the layout as
+// zerolog.TimeFieldFormat: an int64 for the unix formats, a string otherwise
+func timestampValue(t time.Time, layout string) interface{} {
+	switch layout {
+	case zerolog.TimeFormatUnix:
+		return t.Unix()
+	case zerolog.TimeFormatUnixMs:
+		return t.UnixNano() / int64(time.Millisecond)
+	case zerolog.TimeFormatUnixMicro:
+		return t.UnixNano() / int64(time.Microsecond)
+	}
+	return t.Format(layout)
+}
+
+// callerHook adds the caller like zerolog's Context.Caller() formatted with zMarshalCaller()
+// without changing zerolog.CallerMarshalFunc
+type callerHook struct{}
+
+func (callerHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	// skip the frames of zerolog's hook infrastructure like zerolog's caller hook
+	if _, file, line, ok := runtime.Caller(zerolog.CallerSkipFrameCount + 1); ok {
+		e.Str(zerolog.CallerFieldName, zMarshalCaller(file, line))
+	}
+}
+
+// Returns the zerolog parts order for Options.PartsOrder, nil (the zerolog default) if parts is
+// empty or contains unknown parts
+func partsOrder(parts []string) ([]string, error) {
+	if len(parts) == 0 {
+		return nil, nil
+	}
+	order := make([]string, len(parts))
+	for i, part := range parts {
+		switch part {
+		case "time":
+			order[i] = zerolog.TimestampFieldName
+		case "level":
+			order[i] = zerolog.LevelFieldName
+		case "caller":
+			order[i] = zerolog.CallerFieldName
+		case "message":
+			order[i] = zerolog.MessageFieldName
+		default:
+			return nil, fmt.Errorf("unknown part %q", part)
+		}
+	}
+	return order, nil
+}
+
+// Returns Out or stderr if not set
+func (o Options) output() io.Writer {
+	if o.Out == nil {
+		return os.Stderr
+	}
+	return o.Out
+}
+
+// Returns Sampler or a sampler for trace and debug messages if SampleEvery is set
+func (o Options) sampler() zerolog.Sampler {
+	if o.Sampler != nil {
+		return o.Sampler
+	}
+	if o.SampleEvery > 1 {
+		s := &zerolog.BasicSampler{N: uint32(o.SampleEvery)}
+		return zerolog.LevelSampler{TraceSampler: s, DebugSampler: s}
+	}
+	return nil
+}
+
+// Returns the level from LevelName or Level if LevelName is not set or invalid
+func (o Options) level() (int, error) {
+	if o.LevelName == "" {
+		return o.Level, nil
+	}
+	level, err := ParseLevel(o.LevelName)
+	if err != nil {
+		return o.Level, err
 	}
-	return setlevel(zlog.Logger(), o.Level)
+	return level, nil
 }
 
 var _zlog = zerolog.Nop()
@@ -386,92 +1392,381 @@ var _zlog = zerolog.Nop()
 // DisabledLogger is a logger that will never output anything
 var DisabledLogger = _zlog
 
-var loglevel int
+// The level last set with setlevel(), accessed atomically
+var loglevel int32
+
+// Guards log.Logger in SetLevel() and Logl()
+var loggerMu sync.RWMutex
 
 // Return a new logger with given level Logl
 func setlevel(logger zerolog.Logger, level int) zerolog.Logger {
-	loglevel = level
+	atomic.StoreInt32(&loglevel, int32(level))
+	return logger.Level(zerologLevel(level))
+}
+
+// LevelSilent disables all output including fatal messages, e.g. SetLevel(zlog.LevelSilent) for
+// a dry-run. Levels between LevelSilent and -3 are the same as -3 (fatal only).
+const LevelSilent = -100
+
+// Convert zlog level convention to zerolog level
+func zerologLevel(level int) zerolog.Level {
+	if level <= LevelSilent {
+		return zerolog.Disabled
+	}
 	if level < -3 {
 		level = -3
 	}
 	switch level {
 	case -3:
-		return logger.Level(zerolog.FatalLevel)
+		return zerolog.FatalLevel
 	case -2:
-		return logger.Level(zerolog.ErrorLevel)
+		return zerolog.ErrorLevel
 	case -1:
-		return logger.Level(zerolog.WarnLevel)
+		return zerolog.WarnLevel
 	case 0:
-		return logger.Level(zerolog.InfoLevel)
+		return zerolog.InfoLevel
 	case 1:
-		return logger.Level(zerolog.DebugLevel)
+		return zerolog.DebugLevel
 	default:
-		return logger.Level(zerolog.TraceLevel)
+		return zerolog.TraceLevel
 	}
 }
 
-// SetLevel defines the minimum log level for the global Logger
-func SetLevel(level int) { log.Logger = setlevel(log.Logger, level) }
+// ParseLevel converts a level name to the zlog level convention: "trace" is 2, "debug" is 1,
+// "info" is 0, "warn" is -1, "error" is -2, "fatal" is -3 and "silent" is LevelSilent. Names are
+// case-insensitive.
+func ParseLevel(s string) (int, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "trace":
+		return 2, nil
+	case "debug":
+		return 1, nil
+	case "info":
+		return 0, nil
+	case "warn":
+		return -1, nil
+	case "error":
+		return -2, nil
+	case "fatal":
+		return -3, nil
+	case "silent":
+		return LevelSilent, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q", s)
+}
 
-// Logl returns a disable logger if level > loglevel that has been set with SetLevel()
+// SetLevel defines the minimum log level for the global Logger. It is safe to call SetLevel()
+// concurrently with Logl().
+func SetLevel(level int) {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	log.Logger = setlevel(log.Logger, level)
+}
+
+// WithLevel raises the level of the global logger to level while fn runs, e.g. WithLevel(1, fn)
+// logs debug messages of fn. The previous level is restored when fn returns or panics. The
+// level is never lowered. Note that the level is global, concurrent goroutines log with the
+// raised level too.
+func WithLevel(level int, fn func()) {
+	previous := GetLevel()
+	if level > previous {
+		SetLevel(level)
+		defer SetLevel(previous)
+	}
+	fn()
+}
+
+// GetLevel returns the level last set with InitL(), SetLevel() or New()
+func GetLevel() int { return int(atomic.LoadInt32(&loglevel)) }
+
+// Enabled returns whether Logl(level) logs, use it to skip expensive fields:
+//
+//	if zlog.Enabled(2) {
+//		log.Trace().Interface("big", compute()).Send()
+//	}
+func Enabled(level int) bool { return level <= GetLevel() }
+
+// Logl returns a disable logger if level > loglevel that has been set with SetLevel(). Levels above
+// 2 are more verbose steps than trace, e.g. Logl(3) logs with SetLevel(3) but not with SetLevel(2)
+// although zerolog caps both levels at trace. The event is a trace event of the global logger.
 func Logl(level int) *zerolog.Event {
 	//fmt.Printf("zlog(%d), v=%d -> %t\n", level, Options.Verbose, level > Options.Verbose)
-	if level > loglevel {
+	if !Enabled(level) {
 		return DisabledLogger.Trace()
 	}
-	return log.Trace()
+	loggerMu.RLock()
+	l := log.Logger
+	loggerMu.RUnlock()
+	return l.Trace()
+}
+
+// Success logs msg at info level with the field result=ok. The console formats show the level
+// as "OK" (✅ with FormatUnicode) instead of "INF" and drop the result field.
+func Success(msg string) {
+	loggerMu.RLock()
+	l := log.Logger
+	loggerMu.RUnlock()
+	l.Info().Str(ResultFieldName, "ok").Msg(msg)
+}
+
+// LoglOn returns an event for l at the zerolog level for level (0 info, 1 debug, 2 trace,
+// -1 warn, ...). The event is disabled if level is more verbose than the level of l. Unlike
+// Logl(), which checks against the global level of SetLevel(), LoglOn() uses the level of l, e.g.
+// of a subsystem logger created with New(Options{Level: 1}).
+func LoglOn(l zerolog.Logger, level int) *zerolog.Event {
+	return l.WithLevel(zerologLevel(level))
 }
 
-// Tee duplicates logging output to given file
+// Tee duplicates logging output to given file. The file is never closed, use TeeWithCloser()
+// if you need to close the logfile.
 func Tee(fname string, options ...Options) zerolog.Logger {
-	var o Options
-	if len(options) == 0 {
-		o = Options{
-			Overwrite: false,
-			Format:    FormatBW,
+	l, _ := TeeWithCloser(fname, options...)
+	return l
+}
+
+// TeeWithCloser duplicates logging output to given file like Tee(), but additionally returns
+// the closer for the logfile. Use this to close the logfile on shutdown:
+//
+//	logger, closer := zlog.TeeWithCloser("log.json")
+//	defer closer.Close()
+func TeeWithCloser(fname string, options ...Options) (zerolog.Logger, io.Closer) {
+	o := teeOptions(options)
+	fd := mustOpenTeeFile(fname, o)
+	return TeeWriter(fd, o), fd
+}
+
+// NewFile returns a logger like New() that writes to the file fname only. The caller owns the
+// file and closes it with the returned io.Closer. Options.Overwrite, MaxSizeBytes, MaxBackups and
+// RotateDaily apply like with Tee(). Colors are only used with ForceColor. Like NewTest() the
+// global options are not changed.
+func NewFile(fname string, o Options) (zerolog.Logger, io.Closer, error) {
+	fd, err := openTeeFile(fname, o)
+	if err != nil {
+		return DisabledLogger, nil, err
+	}
+	o.Out = fd
+	return newLocalLogger(o), fd, nil
+}
+
+// Logfiles opened by the Tee functions, closed by Close()
+var (
+	teeFilesMu sync.Mutex
+	teeFiles   []*teeCloser
+)
+
+// Close closes all logfiles opened by Tee(), TeeWithCloser() and TeeMulti(). Logfiles are
+// written unbuffered and gzip logfiles are flushed after each message, so no message is lost on
+// log.Fatal() or os.Exit(). A gzip logfile that is not closed lacks the gzip trailer, gunzip
+// reports an unexpected end of file but restores the messages. Long running programs should close
+// the logfiles with
+//
+//	defer zlog.Close()
+//
+// in main.
+func Close() error {
+	teeFilesMu.Lock()
+	defer teeFilesMu.Unlock()
+	var first error
+	for _, fd := range teeFiles {
+		if err := fd.WriteCloser.Close(); err != nil && first == nil {
+			first = err
 		}
-	} else {
-		o = options[0]
 	}
+	teeFiles = nil
+	return first
+}
+
+// Open the logfile for Tee, rotating if Options.RotateDaily or MaxSizeBytes is set
+func openTeeFile(fname string, o Options) (io.WriteCloser, error) {
 	var flag int = os.O_CREATE | os.O_WRONLY
 	if o.Overwrite {
-		flag |= os.O_APPEND
-	} else {
 		flag |= os.O_TRUNC
+	} else {
+		flag |= os.O_APPEND
+	}
+	if o.Gzip && o.Format != FormatJson && o.Format != FormatJsonStd {
+		log.Warn().Str("file", fname).Msg("Gzip is only supported for JSON logfiles")
+		o.Gzip = false
+	}
+	if o.RotateDaily && !o.Gzip {
+		return newDailyWriter(fname, flag)
+	}
+	if o.MaxSizeBytes > 0 && !o.Gzip {
+		return newRotateWriter(fname, flag, o.MaxSizeBytes, o.MaxBackups)
 	}
 	fd, err := os.OpenFile(fname, flag, 0666)
+	if err != nil {
+		return nil, err
+	}
+	if o.Gzip {
+		return newGzipFile(fd), nil
+	}
+	return fd, nil
+}
 
+// Open the logfile for the Tee functions and remember it for Close(). Exits if the logfile
+// cannot be opened.
+func mustOpenTeeFile(fname string, o Options) io.WriteCloser {
+	fd, err := openTeeFile(fname, o)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Cannot tee output")
 	}
-	console := zconsoleWriter(zlogOptions)
+	return addTeeFile(fd)
+}
+
+// Remember the logfile for Close()
+func addTeeFile(fd io.WriteCloser) io.WriteCloser {
+	teeFilesMu.Lock()
+	defer teeFilesMu.Unlock()
+	c := &teeCloser{fd}
+	teeFiles = append(teeFiles, c)
+	return c
+}
+
+// teeCloser is a logfile of the Tee functions that is forgotten by Close() once it is closed, e.g.
+// by daemons reopening their logfiles with TeeWithCloser()
+type teeCloser struct {
+	io.WriteCloser
+}
+
+func (c *teeCloser) Close() error {
+	teeFilesMu.Lock()
+	for i, fd := range teeFiles {
+		if fd == c {
+			teeFiles = append(teeFiles[:i], teeFiles[i+1:]...)
+			break
+		}
+	}
+	teeFilesMu.Unlock()
+	return c.WriteCloser.Close()
+}
+
+// TeeWriter duplicates logging output to the given writer, e.g. a bytes.Buffer or a network
+// connection. The output format is selected with Options.Format like with Tee().
+func TeeWriter(w io.Writer, options ...Options) zerolog.Logger {
+	o := teeOptions(options)
+	return teeLogger(teeFile{w, o})
+}
+
+// TeeTarget is a logfile for TeeMulti() with its own options
+type TeeTarget struct {
+	Filename string
+	Options  Options
+}
 
-	var multi zerolog.LevelWriter
+// TeeMulti duplicates logging output to several logfiles, each with its own format, e.g. a JSON
+// logfile for machine parsing and a BW logfile for humans. The logfiles are closed by Close().
+func TeeMulti(targets []TeeTarget) zerolog.Logger {
+	files := make([]teeFile, len(targets))
+	for i, target := range targets {
+		files[i] = teeFile{mustOpenTeeFile(target.Filename, target.Options), target.Options}
+	}
+	return teeLogger(files...)
+}
+
+// Output of a Tee logger with its options
+type teeFile struct {
+	w io.Writer
+	o Options
+}
+
+// Returns the logger for the Tee functions writing to the console and the files. The console gets
+// the messages of the current level, the files the messages of Options.FileLevel if set. The
+// BaseFields, IncludeHostPID, Caller, Hooks and Sequence options of the console logger apply to all
+// outputs.
+func teeLogger(files ...teeFile) zerolog.Logger {
+	level := GetLevel()
+	verbose := level
+	forensic := false
+	for _, f := range files {
+		forensic = forensic || f.o.ForensicJSON
+	}
+	// the caller and stack of Options.ForensicJSON are only written to the forensic files, the caller
+	// is written to all outputs if the console logger shows it
+	forensicFields := []string{zerolog.ErrorStackFieldName}
+	if !zlogOptions.Caller {
+		forensicFields = append(forensicFields, zerolog.CallerFieldName)
+	}
+	writers := []io.Writer{teeConsole()}
+	if forensic {
+		writers[0] = hideFields(writers[0], forensicFields)
+	}
+	for _, f := range files {
+		out := teeOutput(f.w, f.o)
+		if forensic && !f.o.ForensicJSON {
+			out = hideFields(out, forensicFields)
+		}
+		if f.o.FileLevel != nil {
+			fileLevel := zerologLevel(*f.o.FileLevel)
+			out = levelFilterWriter{out: out, min: func() zerolog.Level { return fileLevel }}
+			if *f.o.FileLevel > verbose {
+				verbose = *f.o.FileLevel
+			}
+		}
+		writers = append(writers, out)
+	}
+	if verbose > level {
+		// the logger passes the messages for the files, keep the console at the current level
+		writers[0] = levelFilterWriter{out: writers[0], min: func() zerolog.Level { return zerologLevel(GetLevel()) }}
+	}
+	// the fields of the console logger, e.g. for log aggregators reading the JSON logfiles
+	ctx := zerolog.New(zerolog.MultiLevelWriter(writers...)).With().Timestamp()
+	if len(zlogOptions.BaseFields) > 0 {
+		ctx = ctx.Fields(zlogOptions.BaseFields)
+	}
+	if zlogOptions.IncludeHostPID {
+		ctx = ctx.Str("host", hostname).Int("pid", os.Getpid())
+	}
+	if forensic {
+		zerolog.ErrorStackMarshaler = ZMarshalStack
+		ctx = ctx.Stack()
+	}
+	m := ctx.Logger()
+	if forensic || zlogOptions.Caller {
+		m = m.Hook(callerHook{})
+	}
+	for _, hook := range zlogOptions.Hooks {
+		m = m.Hook(hook)
+	}
+	if zlogOptions.Sequence {
+		m = m.Hook(sequenceHook{})
+	}
+	return m.Level(zerologLevel(verbose))
+}
+
+// Returns the writer for the Tee output to w in the format selected with Options.Format
+func teeOutput(w io.Writer, o Options) io.Writer {
 	// TODO: could share code with New()?
 	switch o.Format {
 	case FormatJson:
-		multi = zerolog.MultiLevelWriter(console, fd)
+		return w
+	case FormatLogfmt:
+		return logfmtWriter{out: w}
+	case FormatJsonStd:
+		return jsonStdWriter{out: w}
+	case FormatConsole:
+		file := newConsoleWriter(zlogOptions, colorsEnabled(zlogOptions))
+		file.Out = w
+		return file
 	default:
-		sc := SupportColors
-		if o.Format == FormatBW {
-			SupportColors = false
-		}
-		file := zconsoleWriter(zlogOptions)
-		SupportColors = sc
-		file.Out = fd
+		// the console formats write FormatBW like the Tee functions without options
+		file := newConsoleWriter(zlogOptions, false)
+		file.Out = w
 		file.FormatLevel = formatLevelBW
-		if o.Format == FormatBW {
-			file.NoColor = true
-		}
-		multi = zerolog.MultiLevelWriter(console, file)
+		file.NoColor = true
+		return file
 	}
+}
 
-	m := zerolog.New(multi).With().Timestamp().Logger()
-	// restore level
-
-	return setlevel(m, loglevel)
-	//SetLevel(loglevel)
-	//return m
+// Default options for the Tee functions if none are given: append to the logfile in FormatBW
+func teeOptions(options []Options) Options {
+	if len(options) == 0 {
+		return Options{
+			Overwrite: false,
+			Format:    FormatBW,
+		}
+	}
+	return options[0]
 }
 
 // Provide errors that can be returned as standard golang errors.
@@ -480,55 +1775,242 @@ type Error struct {
 	Message string
 	C       zerolog.Context
 	augment int
+	err     error // nested error, see Err() and Unwrap()
+	stack   errors.StackTrace
+	fields  []func(zerolog.Context) zerolog.Context // fields added to C, see AsZerologErrorWith()
 }
 
+// AsZerologError returns the logger with the fields of the first *Error in the chain of e
+// (see errors.As()) and its message, or nil if e does not wrap an *Error
 func AsZerologError(e error) (*zerolog.Logger, string) {
-	if ee, ok := e.(*Error); ok {
-		l := ee.C.Logger()
-		return &l, ee.Message
+	var ee *Error
+	if errors.As(e, &ee) {
+		return ee.Logger(), ee.Message
 	}
 	return nil, ""
 }
 
+// AsZerologErrorWith is like AsZerologError() but adds the fields of the error to base instead
+// of the global logger at the time the error was created, e.g. to log the error with the fields
+// of a request logger. Fields added directly to Error.C are not included.
+func AsZerologErrorWith(e error, base zerolog.Logger) (*zerolog.Logger, string) {
+	var ee *Error
+	if errors.As(e, &ee) {
+		c := base.With()
+		for _, f := range ee.fields {
+			c = f(c)
+		}
+		return ee.logger(c), ee.Message
+	}
+	return nil, ""
+}
+
+// Logger returns a logger with the fields of the error and the stack captured by NewError()
 func (e *Error) Logger() *zerolog.Logger {
-	l := e.C.Logger()
+	return e.logger(e.C)
+}
+
+// Returns a logger for c with the stack of the error
+func (e *Error) logger(c zerolog.Context) *zerolog.Logger {
+	if stack := ZMarshalStack(e); stack != nil {
+		c = c.Interface(zerolog.ErrorStackFieldName, stack)
+	}
+	l := c.Logger()
 	return &l
 }
 
+// MarshalZerologObject adds the message, the fields and the stack of the error to an object,
+// e.g. log.Error().Object("err", e).Send() logs {"err":{"message":"...",<fields>,"stack":"..."}}.
+// Fields added directly to Error.C are not included.
+func (e *Error) MarshalZerologObject(ev *zerolog.Event) {
+	ev.Str("message", e.Message)
+	if len(e.fields) > 0 {
+		// render the fields to JSON and copy them in order
+		var buf bytes.Buffer
+		c := zerolog.New(&buf).With()
+		for _, f := range e.fields {
+			c = f(c)
+		}
+		l := c.Logger()
+		l.Log().Send()
+		dec := json.NewDecoder(&buf)
+		if _, err := dec.Token(); err == nil { // {
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					break
+				}
+				var value json.RawMessage
+				if err := dec.Decode(&value); err != nil {
+					break
+				}
+				ev.RawJSON(key.(string), value)
+			}
+		}
+	}
+	if stack := ZMarshalStack(e); stack != nil {
+		ev.Interface(zerolog.ErrorStackFieldName, stack)
+	}
+}
+
+// StackTrace returns the stack captured by NewError(), nil for NewErrorNoStack()
+func (e *Error) StackTrace() errors.StackTrace { return e.stack }
+
+// Adds a field to C and remembers it for AsZerologErrorWith()
+func (e *Error) add(f func(zerolog.Context) zerolog.Context) *Error {
+	e.C = f(e.C)
+	e.fields = append(e.fields, f)
+	return e
+}
+
 // Augment error by another error
 func (e *Error) Augment(s string) *Error {
 	e.augment++
-	e.C = e.C.Str(fmt.Sprintf("nested#%d", e.augment), s)
-	return e
+	name := fmt.Sprintf("nested#%d", e.augment)
+	return e.add(func(c zerolog.Context) zerolog.Context { return c.Str(name, s) })
 }
 
 func (e *Error) Str(name, value string) *Error {
-	e.C = e.C.Str(name, value)
-	return e
+	return e.add(func(c zerolog.Context) zerolog.Context { return c.Str(name, value) })
 }
 
 func (e *Error) Int(name string, value int) *Error {
-	e.C = e.C.Int(name, value)
-	return e
+	return e.add(func(c zerolog.Context) zerolog.Context { return c.Int(name, value) })
+}
+
+// Hex adds an integer shown in hex like "0x1f4", see Hex()
+func (e *Error) Hex(name string, value int) *Error {
+	return e.add(func(c zerolog.Context) zerolog.Context { return c.Str(name, Hex(value)) })
+}
+
+// Hex returns v in hex like "0x1f4" or "-0x1f4", use it for events with
+// log.Info().Str("flags", zlog.Hex(flags))
+func Hex(v int) string {
+	if v < 0 {
+		return "-0x" + strconv.FormatUint(uint64(-int64(v)), 16)
+	}
+	return "0x" + strconv.FormatInt(int64(v), 16)
+}
+
+func (e *Error) Float64(name string, value float64) *Error {
+	return e.add(func(c zerolog.Context) zerolog.Context { return c.Float64(name, value) })
+}
+
+func (e *Error) Bool(name string, value bool) *Error {
+	return e.add(func(c zerolog.Context) zerolog.Context { return c.Bool(name, value) })
+}
+
+// Interface adds a structured value (struct, map, slice, ...) marshaled as JSON. Note that
+// complex values may be verbose in the console output of Error().
+func (e *Error) Interface(name string, value interface{}) *Error {
+	return e.add(func(c zerolog.Context) zerolog.Context { return c.Interface(name, value) })
+}
+
+// Fields adds all entries of the map, sorted by key
+func (e *Error) Fields(fields map[string]interface{}) *Error {
+	return e.add(func(c zerolog.Context) zerolog.Context { return c.Fields(fields) })
+}
+
+func (e *Error) Dur(name string, value time.Duration) *Error {
+	return e.add(func(c zerolog.Context) zerolog.Context { return c.Dur(name, value) })
 }
 
+func (e *Error) Time(name string, value time.Time) *Error {
+	return e.add(func(c zerolog.Context) zerolog.Context { return c.Time(name, value) })
+}
+
+// Err adds the message of a nested error. The nested error is returned by Unwrap(), so
+// errors.Is() and errors.As() work with the nested error.
 func (e *Error) Err(err error) *Error {
-	e.C = e.C.Str("nested", err.Error())
-	return e
+	e.err = err
+	msg := err.Error()
+	return e.add(func(c zerolog.Context) zerolog.Context { return c.Str("nested", msg) })
+}
+
+// Fatal logs the error with its fields at fatal level and exits the program with os.Exit(1)
+// or the function set with SetExitFunc(). Fatal does not return unless that function returns.
+func (e *Error) Fatal() {
+	e.Logger().WithLevel(zerolog.FatalLevel).Msg(e.Message)
+	exitFunc(1)
+}
+
+// Panic logs the error with its fields at panic level and panics with the message
+func (e *Error) Panic() {
+	e.Logger().Panic().Msg(e.Message)
 }
 
+// Unwrap returns the nested error set with Err() or WrapError()
+func (e *Error) Unwrap() error { return e.err }
+
+// Error returns the message and the fields of the error without color sequences
 func (e *Error) Error() string {
+	return e.render(Options{TimeFormat: "none", Format: FormatBW})
+}
+
+// ColorString returns the message and the fields of the error with color sequences for
+// terminal display. Colors are only disabled with the NO_COLOR environment variable.
+func (e *Error) ColorString() string {
+	return e.render(Options{TimeFormat: "none", Format: FormatColor, ForceColor: true})
+}
+
+func (e *Error) render(o Options) string {
 	var buf bytes.Buffer
-	output := zconsoleWriter(Options{TimeFormat: "none"})
-	output.Out = &buf
+	o.Out = &buf
+	output := newConsoleWriter(o, o.Format != FormatBW && colorsEnabled(o)) // keep the global options
 	l := e.C.Logger().Output(output)
 	l.Log().Msg(e.Message)
 	return strings.TrimSpace(buf.String())
 }
 
+// NewError returns a new error with given message. The stack of the caller is captured and
+// added to the logger returned by Logger() and AsZerologError().
 func NewError(msg string) *Error {
+	return newError(msg, 4)
+}
+
+// NewErrorSkip is like NewError() but skips the given number of frames on top of the stack.
+// Use this in helper functions creating errors, with skip 1 the stack starts at the caller of
+// the helper function.
+func NewErrorSkip(skip int, msg string) *Error {
+	return newError(msg, 4+skip)
+}
+
+// skip is the number of frames to skip, see callers()
+func newError(msg string, skip int) *Error {
+	return &Error{
+		Message: msg,
+		C:       log.With(),
+		stack:   callers(skip),
+	}
+}
+
+// WrapError returns a new error for err with the message "msg: <message of err>". The stack of the
+// caller is captured like with NewError(), err is returned by Unwrap(), so errors.Is() and
+// errors.As() work with err.
+func WrapError(err error, msg string) *Error {
+	if err == nil {
+		return newError(msg, 4)
+	}
+	e := newError(msg+": "+err.Error(), 4)
+	e.err = err
+	return e
+}
+
+// NewErrorNoStack is like NewError() but does not capture the stack, which is cheaper
+func NewErrorNoStack(msg string) *Error {
 	return &Error{
 		Message: msg,
 		C:       log.With(),
 	}
 }
+
+// Returns the stack, skip is the number of frames to skip with 0 being runtime.Callers
+func callers(skip int) errors.StackTrace {
+	var pcs [32]uintptr
+	n := runtime.Callers(skip, pcs[:])
+	st := make(errors.StackTrace, n)
+	for i := 0; i < n; i++ {
+		st[i] = errors.Frame(pcs[i])
+	}
+	return st
+}
@@ -1,5 +1,27 @@
 package zlog
 
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
 // benchmark memory for simple pointer including struct
 
 //func BenchmarkGetLogger(b *testing.B) {
@@ -17,3 +39,1671 @@ package zlog
 //		}
 //	}
 //}
+
+// Returns a logger with given options writing to a buffer instead of stderr
+func newBufferLogger(o Options) (*bytes.Buffer, func(msg string)) {
+	l, buf := NewTest(o)
+	return buf, func(msg string) {
+		l.Warn().Str("file", "hosts").Msg(msg)
+		l.Error().Err(os.ErrNotExist).Msg(msg)
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	for _, format := range []LogOutputFormat{FormatColor, FormatUnicode} {
+		buf, logmsg := newBufferLogger(Options{Format: format, ForceColor: true})
+		logmsg("with colors")
+		if !strings.Contains(buf.String(), "\033[") {
+			t.Errorf("format %d: expected color sequences in %q", format, buf.String())
+		}
+
+		os.Setenv("NO_COLOR", "")
+		buf, logmsg = newBufferLogger(Options{Format: format, ForceColor: true})
+		logmsg("without colors")
+		os.Unsetenv("NO_COLOR")
+		if strings.Contains(buf.String(), "\033[") {
+			t.Errorf("format %d: unexpected color sequences in %q", format, buf.String())
+		}
+	}
+}
+
+func TestForceColor(t *testing.T) {
+	sc := SupportColors
+	defer func() { SupportColors = sc }()
+	SupportColors = false
+
+	buf, logmsg := newBufferLogger(Options{Format: FormatColor})
+	logmsg("no terminal")
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("unexpected color sequences in %q", buf.String())
+	}
+
+	buf, logmsg = newBufferLogger(Options{Format: FormatColor, ForceColor: true})
+	logmsg("forced by option")
+	if !strings.Contains(buf.String(), Orange+"WRN") {
+		t.Errorf("expected color sequences in %q", buf.String())
+	}
+
+	os.Setenv("FORCE_COLOR", "1")
+	defer os.Unsetenv("FORCE_COLOR")
+	buf, logmsg = newBufferLogger(Options{Format: FormatColor})
+	logmsg("forced by environment")
+	if !strings.Contains(buf.String(), "\033[") {
+		t.Errorf("expected color sequences in %q", buf.String())
+	}
+
+	buf, logmsg = newBufferLogger(Options{Format: FormatColor, NoColor: true})
+	logmsg("disabled by option")
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("unexpected color sequences in %q", buf.String())
+	}
+
+	// NO_COLOR wins
+	os.Setenv("NO_COLOR", "1")
+	defer os.Unsetenv("NO_COLOR")
+	buf, logmsg = newBufferLogger(Options{Format: FormatColor, ForceColor: true})
+	logmsg("NO_COLOR and FORCE_COLOR")
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("unexpected color sequences in %q", buf.String())
+	}
+}
+
+func TestColorValues(t *testing.T) {
+	buf, logmsg := newBufferLogger(Options{Format: FormatColor, ForceColor: true, ColorValues: true})
+	logmsg("colored values")
+	if !strings.Contains(buf.String(), Cyan+"file="+ResetColor+Gray+"hosts"+ResetColor) {
+		t.Errorf("expected gray value in %q", buf.String())
+	}
+
+	buf, logmsg = newBufferLogger(Options{Format: FormatBW, ForceColor: true, ColorValues: true})
+	logmsg("bw")
+	if strings.Contains(buf.String(), Gray) {
+		t.Errorf("unexpected color sequences in %q", buf.String())
+	}
+}
+
+func TestColorBools(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Format: FormatColor, ForceColor: true, ColorBools: true, TimeFormat: "none", Out: &buf})
+	l.Info().Bool("ok", true).Bool("retry", false).Str("text", "true").Msg("bools")
+	for _, expected := range []string{Green + "true" + ResetColor, Red + "false" + ResetColor, "text=" + ResetColor + "true"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in %q", expected, buf.String())
+		}
+	}
+
+	buf.Reset()
+	l = New(Options{Format: FormatBW, ForceColor: true, ColorBools: true, TimeFormat: "none", Out: &buf})
+	l.Info().Bool("ok", true).Bool("retry", false).Msg("bw")
+	if expected := "INF bw ok=true retry=false\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestFormatCompact(t *testing.T) {
+	for level, expected := range map[string]string{"trace": Gray + "T", "debug": Gray + "D", "info": Green + "I", "warn": Orange + "W",
+		"error": Red + "E", "fatal": Red + "F", "panic": Red + "P"} {
+		if s := formatLevelCompact(true)(level); s != expected+ResetColor {
+			t.Errorf("%s: expected %q, got %q", level, expected+ResetColor, s)
+		}
+		if s := formatLevelCompact(false)(level); s != expected[len(expected)-1:] {
+			t.Errorf("%s: expected %q without colors, got %q", level, expected[len(expected)-1:], s)
+		}
+	}
+
+	var buf bytes.Buffer
+	l := New(Options{Format: FormatCompact, TimeFormat: "none", Out: &buf})
+	l.Warn().Str("file", "hosts").Msg("compact")
+	if expected := "W compact file=hosts\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+	buf.Reset()
+	l = New(Options{Format: FormatCompact, TimeFormat: "none", Out: &buf, ForceColor: true})
+	l.Warn().Msg("compact")
+	if !strings.HasPrefix(buf.String(), Orange+"W"+ResetColor) {
+		t.Errorf("expected orange level in %q", buf.String())
+	}
+}
+
+func TestNamedColorize(t *testing.T) {
+	if s := NamedColorize("red", "error"); s != Red+"error"+ResetColor {
+		t.Errorf("unexpected %q", s)
+	}
+	if s := NamedColorize("bogus", "text"); s != "text" {
+		t.Errorf("expected text unchanged for unknown color, got %q", s)
+	}
+	if s := "bold " + NamedColorize("bogus", "text"); strings.Contains(s, ResetColor) {
+		t.Errorf("unexpected reset in %q", s)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	if color, err := ParseColor("orange"); err != nil || color != Orange {
+		t.Errorf("unexpected %q, %v", color, err)
+	}
+	if _, err := ParseColor("bogus"); err == nil {
+		t.Errorf("expected error for unknown color")
+	}
+}
+
+func TestRGBColorize(t *testing.T) {
+	if s := RGBColorize(255, 135, 0, "warn"); s != "\033[38;2;255;135;0mwarn"+ResetColor {
+		t.Errorf("unexpected %q", s)
+	}
+}
+
+func TestTrueColor(t *testing.T) {
+	buf, logmsg := newBufferLogger(Options{Format: FormatColor, ForceColor: true, TrueColor: true})
+	logmsg("truecolor")
+	for _, expected := range []string{"\033[38;2;255;135;0mWRN" + ResetColor, "\033[38;2;0;215;255mfile="} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in %q", expected, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "38;5;") {
+		t.Errorf("unexpected 256 color sequence in %q", buf.String())
+	}
+
+	buf, logmsg = newBufferLogger(Options{Format: FormatColor, ForceColor: true})
+	logmsg("256 colors")
+	if strings.Contains(buf.String(), "38;2;") || !strings.Contains(buf.String(), Orange+"WRN") {
+		t.Errorf("expected 256 colors by default in %q", buf.String())
+	}
+}
+
+func TestHighlightErrors(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Format: FormatColor, ForceColor: true, HighlightErrors: true, TimeFormat: "none", Out: &buf})
+	l.Warn().Msg("warning")
+	l.Error().Msg("error")
+	l.WithLevel(zerolog.FatalLevel).Msg("fatal")
+	lines := strings.Split(buf.String(), "\n")
+	if strings.Contains(lines[0], DarkRedBackground) {
+		t.Errorf("unexpected background for warning in %q", lines[0])
+	}
+	for _, line := range lines[1:3] {
+		if !strings.HasPrefix(line, DarkRedBackground+Red) {
+			t.Errorf("expected background in %q", line)
+		}
+	}
+
+	buf.Reset()
+	l = New(Options{Format: FormatColor, NoColor: true, HighlightErrors: true, TimeFormat: "none", Out: &buf})
+	l.Error().Msg("error")
+	if expected := "ERR error\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestPalette(t *testing.T) {
+	p := Palette()
+	if p["green"] != Green || p["lightblue"] != LightBlue {
+		t.Errorf("unexpected palette %q", p)
+	}
+	p["green"] = Red
+	if NamedColorize("green", "ok") != Green+"ok"+ResetColor {
+		t.Errorf("palette is not a copy")
+	}
+}
+
+func TestAlignLevel(t *testing.T) {
+	for _, format := range []LogOutputFormat{FormatUnicode, FormatColor, FormatBW} {
+		output := zconsoleWriter(Options{Format: format, ForceColor: true, AlignLevel: true})
+		for level, width := range map[string]int{"trace": 3, "info": 3, "error": 3, "log": 3, "": 7} {
+			if w := displayWidth(output.FormatLevel(level)); w != width {
+				t.Errorf("format %d: expected width %d for level %q, got %d", format, width, level, w)
+			}
+		}
+	}
+	output := zconsoleWriter(Options{Format: FormatUnicode, AlignLevel: true})
+	for level, expected := range map[string]string{"trace": "🔹 ", "info": "🟢 ", "log": "LOG", "": "nolevel"} {
+		if s := output.FormatLevel(level); s != expected {
+			t.Errorf("expected %q for level %q, got %q", expected, level, s)
+		}
+	}
+}
+
+func TestMaxMessageLen(t *testing.T) {
+	for s, expected := range map[string]string{"": "", "short": "short", "exactly10!": "exactly10!",
+		"longer than 10": "longer tha…", "grüße aus köln": "grüße aus …", "äöüäöüäöüä": "äöüäöüäöüä",
+		"äöüäöüäöüäö": "äöüäöüäöüä…", "日本語のメッセージです": "日本語のメッセージで…"} {
+		if got := truncate(s, 10); got != expected {
+			t.Errorf("truncate(%q): expected %q, got %q", s, expected, got)
+		}
+	}
+
+	var buf bytes.Buffer
+	l := New(Options{Format: FormatBW, TimeFormat: "none", MaxMessageLen: 10, Out: &buf})
+	l.Info().Str("file", "hosts").Msg("grüße aus köln")
+	if expected := "INF grüße aus … file=hosts\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	var js bytes.Buffer
+	buf.Reset()
+	tee := TeeWriter(&js, Options{Format: FormatJson})
+	tee.Info().Msg("grüße aus köln")
+	if !strings.Contains(js.String(), `"grüße aus köln"`) || !strings.Contains(buf.String(), "grüße aus …") {
+		t.Errorf("expected full message in JSON %q and truncated on console %q", js.String(), buf.String())
+	}
+}
+
+func TestFieldSeparator(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Format: FormatBW, TimeFormat: "none", FieldSeparator: "|", Out: &buf})
+	l.Info().Str("host", "localhost").Int("port", 22).Msg("Connected")
+	if expected := "INF Connected | host=localhost | port=22\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	l = New(Options{Format: FormatColor, ForceColor: true, TimeFormat: "none", FieldSeparator: "·", Out: &buf})
+	l.Error().Err(os.ErrNotExist).Str("file", "hosts").Msg("Cannot open")
+	if !strings.Contains(buf.String(), "Cannot open · "+Red+"error="+ResetColor+`"file does not exist" · `+Cyan+"file=") {
+		t.Errorf("expected separators in %q", buf.String())
+	}
+}
+
+func TestSortedFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Format: FormatBW, TimeFormat: "none", Out: &buf})
+	l.Info().Str("zone", "eu").Int("port", 22).Str("host", "localhost").Msg("first")
+	l.Info().Str("host", "localhost").Str("zone", "eu").Int("port", 22).Msg("second")
+	l.Error().Str("zone", "eu").Err(os.ErrNotExist).Str("host", "localhost").Msg("third")
+	expected := "INF first host=localhost port=22 zone=eu\n" +
+		"INF second host=localhost port=22 zone=eu\n" +
+		`ERR third error="file does not exist" host=localhost zone=eu` + "\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestPartsOrder(t *testing.T) {
+	defer func(ts func() time.Time) { zerolog.TimestampFunc = ts }(zerolog.TimestampFunc)
+	zerolog.TimestampFunc = func() time.Time { return time.Date(2022, 2, 6, 12, 34, 56, 0, time.Local) }
+
+	for _, tc := range []struct {
+		parts    []string
+		expected string
+	}{
+		{nil, "2022-02-06 12:34:56 INF Connected host=localhost\n"},
+		{[]string{"level", "time", "message"}, "INF 2022-02-06 12:34:56 Connected host=localhost\n"},
+		{[]string{"message"}, "Connected host=localhost\n"},
+		{[]string{"level", "bogus"}, "2022-02-06 12:34:56 INF Connected host=localhost\n"},
+	} {
+		var buf bytes.Buffer
+		l := New(Options{Format: FormatBW, PartsOrder: tc.parts, Out: &buf})
+		buf.Reset() // drop warning
+		l.Info().Str("host", "localhost").Msg("Connected")
+		if buf.String() != tc.expected {
+			t.Errorf("%q: expected %q, got %q", tc.parts, tc.expected, buf.String())
+		}
+	}
+}
+
+func TestNoTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	l := New(Options{Format: FormatColor})
+	l.Warn().Str("file", "hosts").Msg("stderr is a pipe")
+	w.Close()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "WRN stderr is a pipe") || strings.Contains(string(b), "\033[") {
+		t.Errorf("unexpected output %q", b)
+	}
+}
+
+func TestOut(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Format: FormatColor, Out: &buf})
+	l.Info().Msg("to buffer")
+	if !strings.Contains(buf.String(), "INF to buffer") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	buf.Reset()
+	l = New(Options{Format: FormatLogfmt, Out: &buf})
+	l.Info().Msg("logfmt")
+	if !strings.Contains(buf.String(), "_zm=logfmt") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestBaseFields(t *testing.T) {
+	logger := log.Logger
+	defer func() { log.Logger = logger }()
+
+	var buf bytes.Buffer
+	log.Logger = New(Options{Format: FormatBW, TimeFormat: "none", Out: &buf,
+		BaseFields: map[string]interface{}{"version": "1.2.3", "service": "foo", "pid": 42}})
+	log.Info().Str("file", "hosts").Msg("unrelated")
+	expected := "INF unrelated file=hosts pid=42 service=foo version=1.2.3\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestHex(t *testing.T) {
+	for v, expected := range map[int]string{0: "0x0", 500: "0x1f4", -500: "-0x1f4", 255: "0xff", -1: "-0x1"} {
+		if s := Hex(v); s != expected {
+			t.Errorf("expected %q for %d, got %q", expected, v, s)
+		}
+	}
+
+	e := NewErrorNoStack("Bad flags").Hex("flags", 500).Hex("offset", -16).Hex("zero", 0)
+	if expected := "Bad flags flags=0x1f4 offset=-0x10 zero=0x0"; e.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, e.Error())
+	}
+	var buf bytes.Buffer
+	l := e.Logger().Output(&buf)
+	l.Error().Msg(e.Message)
+	if !strings.Contains(buf.String(), `"flags":"0x1f4","offset":"-0x10","zero":"0x0"`) {
+		t.Errorf("unexpected JSON %q", buf.String())
+	}
+}
+
+func TestSuccess(t *testing.T) {
+	logger := log.Logger
+	defer func() { log.Logger = logger }()
+
+	for format, expected := range map[LogOutputFormat]string{FormatBW: "OK Done\n", FormatUnicode: "✅ Done\n",
+		FormatColor: Green + "OK" + ResetColor + " Done\n"} {
+		var buf bytes.Buffer
+		log.Logger = New(Options{Format: format, ForceColor: true, TimeFormat: "none", Out: &buf})
+		Success("Done")
+		if buf.String() != expected {
+			t.Errorf("format %d: expected %q, got %q", format, expected, buf.String())
+		}
+	}
+
+	var js bytes.Buffer
+	log.Logger = TeeWriter(&js, Options{Format: FormatJson})
+	Success("Done")
+	if !strings.Contains(js.String(), `"_zl":"info"`) || !strings.Contains(js.String(), `"result":"ok"`) {
+		t.Errorf("expected info level and result field in %q", js.String())
+	}
+}
+
+func TestWithLevel(t *testing.T) {
+	defer SetLevel(GetLevel())
+	SetLevel(0)
+
+	var during int
+	WithLevel(1, func() { during = GetLevel() })
+	if during != 1 || GetLevel() != 0 {
+		t.Errorf("expected level 1 during and 0 after, got %d and %d", during, GetLevel())
+	}
+
+	WithLevel(-1, func() { during = GetLevel() })
+	if during != 0 {
+		t.Errorf("expected level not to be lowered, got %d", during)
+	}
+
+	func() {
+		defer func() { recover() }()
+		WithLevel(2, func() { panic("boom") })
+	}()
+	if GetLevel() != 0 {
+		t.Errorf("expected level 0 after panic, got %d", GetLevel())
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	defer SetLevel(GetLevel())
+	SetLevel(1)
+	for level, expected := range map[int]bool{-2: true, 0: true, 1: true, 2: false, 3: false} {
+		if Enabled(level) != expected {
+			t.Errorf("level 1: expected Enabled(%d) = %t", level, expected)
+		}
+	}
+	SetLevel(-1)
+	if Enabled(0) || !Enabled(-1) {
+		t.Errorf("level -1: expected only warnings and errors enabled")
+	}
+}
+
+func TestLoglOn(t *testing.T) {
+	defer SetLevel(GetLevel())
+	db, dbBuf := NewTest(Options{Format: FormatBW, TimeFormat: "none", Level: 1})
+	http, httpBuf := NewTest(Options{Format: FormatBW, TimeFormat: "none", Level: -1})
+	for level := -2; level <= 2; level++ {
+		LoglOn(db, level).Int("level", level).Msg("db")
+		LoglOn(http, level).Int("level", level).Msg("http")
+	}
+	if expected := "ERR db level=-2\nWRN db level=-1\nINF db level=0\nDBG db level=1\n"; dbBuf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, dbBuf.String())
+	}
+	if expected := "ERR http level=-2\nWRN http level=-1\n"; httpBuf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, httpBuf.String())
+	}
+}
+
+func TestInitContainer(t *testing.T) {
+	defer func(l zerolog.Logger, level int, tf, ts, lv, msg string) {
+		log.Logger = setlevel(l, level)
+		zerolog.TimeFieldFormat, zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName = tf, ts, lv, msg
+	}(log.Logger, GetLevel(), zerolog.TimeFieldFormat, zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName)
+	console := zlogOptions
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	stdout := os.Stdout
+	os.Stdout = w
+	InitContainer(0)
+	os.Stdout = stdout
+
+	log.Debug().Msg("suppressed")
+	log.Info().Str("file", "hosts").Msg("Creating file")
+	w.Close()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var evt map[string]interface{}
+	if err := json.Unmarshal(b, &evt); err != nil {
+		t.Fatalf("expected a single JSON line, got %q: %s", b, err)
+	}
+	if evt["level"] != "info" || evt["message"] != "Creating file" || evt["file"] != "hosts" {
+		t.Errorf("unexpected event %q", b)
+	}
+	if _, err := time.Parse(time.RFC3339, evt["time"].(string)); err != nil {
+		t.Errorf("expected RFC3339 time in %q", b)
+	}
+	if !reflect.DeepEqual(zlogOptions, console) {
+		t.Errorf("expected unchanged console options, got %+v", zlogOptions)
+	}
+}
+
+func TestIncludeHostPID(t *testing.T) {
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skip("no hostname:", err)
+	}
+	var buf bytes.Buffer
+	l := New(Options{IncludeHostPID: true}).Output(&buf) // JSON
+	l.Info().Msg("enriched")
+	var evt struct {
+		Host string `json:"host"`
+		Pid  int    `json:"pid"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &evt); err != nil {
+		t.Fatal(err)
+	}
+	if evt.Host != host || evt.Pid != os.Getpid() {
+		t.Errorf("expected host %q and pid %d in %q", host, os.Getpid(), buf.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for s, expected := range map[string]int{"trace": 2, "Debug": 1, " info ": 0, "WARN": -1, "error": -2, "fatal": -3, "Silent": LevelSilent} {
+		level, err := ParseLevel(s)
+		if err != nil || level != expected {
+			t.Errorf("ParseLevel(%q) = %d, %v, expected %d", s, level, err, expected)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Errorf("expected error for unknown level")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for s, expected := range map[string]LogOutputFormat{"color": FormatColor, "BW": FormatBW, " json ": FormatJson, "Unicode": FormatUnicode} {
+		format, err := ParseFormat(s)
+		if err != nil || format != expected {
+			t.Errorf("ParseFormat(%q) = %d, %v, expected %d", s, format, err, expected)
+		}
+	}
+	for _, s := range []string{"fancy", "", "2"} {
+		if _, err := ParseFormat(s); err == nil {
+			t.Errorf("expected error for unknown format %q", s)
+		}
+	}
+}
+
+func TestFormatString(t *testing.T) {
+	for _, format := range []LogOutputFormat{FormatColor, FormatBW, FormatJson, FormatUnicode, FormatLogfmt, FormatJsonStd, FormatColorLight, FormatConsole, FormatCompact} {
+		parsed, err := ParseFormat(format.String())
+		if err != nil || parsed != format {
+			t.Errorf("format %d: %q parsed as %d, %v", format, format.String(), parsed, err)
+		}
+	}
+	if s := LogOutputFormat(42).String(); s != "LogOutputFormat(42)" {
+		t.Errorf("unexpected name %q for unknown format", s)
+	}
+}
+
+func TestFormatText(t *testing.T) {
+	var config struct {
+		Format LogOutputFormat `json:"format"`
+	}
+	if err := json.Unmarshal([]byte(`{"format": "Unicode"}`), &config); err != nil || config.Format != FormatUnicode {
+		t.Fatalf("expected FormatUnicode, got %d, %v", config.Format, err)
+	}
+	b, err := json.Marshal(config)
+	if err != nil || string(b) != `{"format":"unicode"}` {
+		t.Errorf("unexpected JSON %s, %v", b, err)
+	}
+	if err := json.Unmarshal([]byte(`{"format": "fancy"}`), &config); err == nil {
+		t.Errorf("expected error for unknown format")
+	}
+}
+
+func TestGetLevel(t *testing.T) {
+	defer func(l zerolog.Logger, level int) { log.Logger = setlevel(l, level) }(log.Logger, GetLevel())
+	log.Logger = New(Options{Level: 2})
+	if GetLevel() != 2 {
+		t.Errorf("expected level 2, got %d", GetLevel())
+	}
+	SetLevel(-1)
+	if GetLevel() != -1 {
+		t.Errorf("expected level -1, got %d", GetLevel())
+	}
+}
+
+func TestLoglVerbose(t *testing.T) {
+	defer func(l zerolog.Logger, level int) { log.Logger = setlevel(l, level) }(log.Logger, GetLevel())
+	var buf bytes.Buffer
+	for _, test := range []struct {
+		level    int
+		expected string
+	}{
+		{2, "TRC level 2\n"},
+		{3, "TRC level 2\nTRC level 3\n"},
+		{4, "TRC level 2\nTRC level 3\nTRC level 4\n"},
+	} {
+		buf.Reset()
+		log.Logger = New(Options{Level: test.level, Format: FormatBW, TimeFormat: "none", Out: &buf})
+		for level := 2; level <= 5; level++ {
+			Logl(level).Msgf("level %d", level)
+		}
+		if buf.String() != test.expected {
+			t.Errorf("level %d: expected %q, got %q", test.level, test.expected, buf.String())
+		}
+	}
+}
+
+func TestConcurrentSetLevel(t *testing.T) {
+	defer func(l zerolog.Logger, level int) { log.Logger = setlevel(l, level) }(log.Logger, GetLevel())
+	log.Logger = zerolog.New(io.Discard)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 1000; n++ {
+				Logl(1).Int("n", n).Msg("message")
+			}
+		}()
+	}
+	for n := 0; n < 1000; n++ {
+		SetLevel(n % 3)
+	}
+	wg.Wait()
+}
+
+func TestErrorFields(t *testing.T) {
+	err := NewError("x").Int("n", 3).Bool("retry", true).Float64("ratio", 0.5)
+
+	s := err.Error()
+	for _, expected := range []string{"x", "n=3", "retry=true", "ratio=0.5"} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("expected %q in %q", expected, s)
+		}
+	}
+
+	var buf bytes.Buffer
+	l, msg := AsZerologError(err)
+	if l == nil {
+		t.Fatal("expected logger")
+	}
+	out := l.Output(&buf)
+	out.Error().Msg(msg)
+	for _, expected := range []string{`"n":3`, `"retry":true`, `"ratio":0.5`, `"x"`} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in %q", expected, buf.String())
+		}
+	}
+}
+
+func TestAsZerologErrorWrapped(t *testing.T) {
+	e := NewErrorNoStack("Cannot open file").Str("file", "hosts")
+	for _, err := range []error{e, fmt.Errorf("config: %w", e), fmt.Errorf("startup: %w", fmt.Errorf("config: %w", e))} {
+		l, msg := AsZerologError(err)
+		if l == nil || msg != "Cannot open file" {
+			t.Errorf("%v: expected logger and message, got %v %q", err, l, msg)
+			continue
+		}
+		var buf bytes.Buffer
+		out := l.Output(&buf)
+		out.Error().Msg(msg)
+		if !strings.Contains(buf.String(), `"file":"hosts"`) {
+			t.Errorf("expected field in %q", buf.String())
+		}
+	}
+	if l, _ := AsZerologError(fmt.Errorf("config: %w", os.ErrNotExist)); l != nil {
+		t.Errorf("expected nil logger without *Error")
+	}
+}
+
+func TestErrorPanic(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.Logger
+	defer func() { log.Logger = logger }()
+	log.Logger = zerolog.New(&buf)
+
+	defer func() {
+		if r := recover(); r != "Cannot open file" {
+			t.Errorf("expected panic with message, got %v", r)
+		}
+		if !strings.Contains(buf.String(), `"file":"hosts"`) || !strings.Contains(buf.String(), `"panic"`) {
+			t.Errorf("unexpected output %q", buf.String())
+		}
+	}()
+	NewErrorNoStack("Cannot open file").Str("file", "hosts").Panic()
+}
+
+// Fatal exits, so run it in a subprocess
+func TestErrorFatal(t *testing.T) {
+	if os.Getenv("ZLOG_TEST_FATAL") == "1" {
+		log.Logger = zerolog.New(os.Stderr)
+		NewErrorNoStack("Cannot open file").Str("file", "hosts").Fatal()
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestErrorFatal$")
+	cmd.Env = append(os.Environ(), "ZLOG_TEST_FATAL=1")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		t.Errorf("expected exit code 1, got %v", err)
+	}
+	if !strings.Contains(stderr.String(), `"file":"hosts"`) || !strings.Contains(stderr.String(), `"fatal"`) {
+		t.Errorf("unexpected output %q", stderr.String())
+	}
+}
+
+func TestExitFunc(t *testing.T) {
+	logger := log.Logger
+	defer func() { log.Logger = logger }()
+	defer SetExitFunc(nil)
+
+	code := -1
+	var buf bytes.Buffer
+	log.Logger = New(Options{Format: FormatBW, TimeFormat: "none", Out: &buf})
+	SetExitFunc(func(c int) { code = c })
+	NewErrorNoStack("Cannot open file").Str("file", "hosts").Fatal()
+	if code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+	if expected := "FTL Cannot open file file=hosts\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestLevelSilent(t *testing.T) {
+	logger, level := log.Logger, GetLevel()
+	defer func() { log.Logger = setlevel(logger, level) }()
+	defer SetExitFunc(nil)
+
+	code := -1
+	var buf bytes.Buffer
+	log.Logger = New(Options{Format: FormatBW, Out: &buf})
+	SetExitFunc(func(c int) { code = c })
+	SetLevel(LevelSilent)
+	log.Error().Msg("error")
+	log.Fatal().Msg("fatal") // the event is disabled, zerolog does not call os.Exit
+	NewErrorNoStack("Cannot open file").Fatal()
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got %q", buf.String())
+	}
+	if code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+	if Enabled(-3) {
+		t.Errorf("expected fatal level disabled")
+	}
+
+	SetLevel(-5) // levels below -3 still log fatal messages
+	if log.Logger.GetLevel() != zerolog.FatalLevel {
+		t.Errorf("expected fatal level for -5, got %s", log.Logger.GetLevel())
+	}
+}
+
+func TestErrorInterface(t *testing.T) {
+	err := NewError("x").Interface("host", map[string]interface{}{"name": "localhost", "port": 22}).Dur("timeout", 1500*time.Millisecond)
+
+	s := err.Error()
+	for _, expected := range []string{`host={"name":"localhost","port":22}`, "timeout=1500"} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("expected %q in %q", expected, s)
+		}
+	}
+}
+
+type customError struct{ code int }
+
+func (e *customError) Error() string { return fmt.Sprintf("custom error %d", e.code) }
+
+func TestErrorUnwrap(t *testing.T) {
+	err := NewError("read failed").Err(io.EOF)
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("expected errors.Is(err, io.EOF)")
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("unexpected errors.Is(err, io.ErrUnexpectedEOF)")
+	}
+
+	err = NewError("request failed").Err(&customError{code: 42})
+	var ce *customError
+	if !errors.As(err, &ce) || ce.code != 42 {
+		t.Errorf("expected errors.As to find customError")
+	}
+	if !strings.Contains(err.Error(), "custom error 42") {
+		t.Errorf("expected nested message in %q", err.Error())
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	defer dropTestingStack()()
+	_, _, line, _ := runtime.Caller(0)
+	err := WrapError(fmt.Errorf("open hosts: %w", os.ErrNotExist), "Cannot read config").Str("file", "hosts")
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected errors.Is(err, os.ErrNotExist)")
+	}
+	if expected := "Cannot read config: open hosts: file does not exist file=hosts"; err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+	if frame, expected := topFrame(err), fmt.Sprintf("zlog_test.go:%d", line+1); frame != expected {
+		t.Errorf("expected stack to start at %s, got %s", expected, frame)
+	}
+
+	if err := WrapError(nil, "no error"); err.Unwrap() != nil || err.Error() != "no error" {
+		t.Errorf("unexpected error for nil: %q", err.Error())
+	}
+}
+
+// Drop the frames of the testing package from stacks
+func dropTestingStack() func() {
+	drop := ZlogDropStack
+	ZlogDropStack = 2 // testing.tRunner, runtime.goexit
+	return func() { ZlogDropStack = drop }
+}
+
+func TestErrorStack(t *testing.T) {
+	defer dropTestingStack()()
+	var buf bytes.Buffer
+	l := NewError("x").Logger().Output(&buf)
+	l.Error().Send()
+	if !strings.Contains(buf.String(), `"stack":"zlog_test.go:`) {
+		t.Errorf("expected caller in stack %q", buf.String())
+	}
+
+	buf.Reset()
+	l = NewErrorNoStack("x").Logger().Output(&buf)
+	l.Error().Send()
+	if strings.Contains(buf.String(), `"stack"`) {
+		t.Errorf("unexpected stack %q", buf.String())
+	}
+}
+
+func TestErrorObject(t *testing.T) {
+	defer dropTestingStack()()
+	zconsoleWriter(Options{}) // set zlog field names
+	var buf bytes.Buffer
+	l := zerolog.New(&buf)
+	e := NewErrorNoStack("Cannot open file").Str("file", "hosts").Int("n", 3).Dur("timeout", 1500*time.Millisecond).Err(os.ErrNotExist)
+	l.Error().Object("err", e).Msg("request failed")
+	expected := `{"_zl":"error","err":{"message":"Cannot open file","file":"hosts","n":3,"timeout":1500,"nested":"file does not exist"},"_zm":"request failed"}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	l.Error().Object("err", NewError("x")).Send()
+	var evt struct {
+		Err map[string]interface{} `json:"err"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &evt); err != nil {
+		t.Fatal(err)
+	}
+	if stack, ok := evt.Err["stack"].(string); evt.Err["message"] != "x" || !ok || !strings.HasPrefix(stack, "zlog_test.go:") {
+		t.Errorf("expected message and stack in %q", buf.String())
+	}
+}
+
+func TestAsZerologErrorWith(t *testing.T) {
+	logger := log.Logger
+	defer func() { log.Logger = logger }()
+
+	zconsoleWriter(Options{}) // set zlog field names
+	log.Logger = zerolog.New(nil).With().Str("stale", "global").Logger()
+	e := NewErrorNoStack("Cannot open file").Str("file", "hosts").Int("n", 3).Err(os.ErrNotExist)
+
+	var buf bytes.Buffer
+	request := zerolog.New(&buf).With().Str("request", "42").Logger()
+	l, msg := AsZerologErrorWith(e, request)
+	l.Error().Msg(msg)
+	expected := `{"_zl":"error","request":"42","file":"hosts","n":3,"nested":"file does not exist","_zm":"Cannot open file"}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	if l, _ := AsZerologErrorWith(os.ErrNotExist, request); l != nil {
+		t.Errorf("expected nil logger for other errors")
+	}
+}
+
+func TestErrorFieldsMap(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewErrorNoStack("x").Fields(map[string]interface{}{"b": 2, "c": "three", "a": true}).Logger().Output(&buf)
+	l.Log().Send()
+	if !strings.Contains(buf.String(), `"a":true,"b":2,"c":"three"`) {
+		t.Errorf("expected sorted fields in %q", buf.String())
+	}
+}
+
+func TestRelativeTimestamp(t *testing.T) {
+	defer func(clock func() time.Time, startup time.Time) { now = clock; SetStartTime(startup) }(now, StartTime())
+	SetStartTime(time.Date(2022, 2, 6, 12, 34, 56, 0, time.Local))
+	now = func() time.Time { return StartTime().Add(4412 * time.Millisecond) }
+
+	for tf, expected := range map[string]string{"s": "[0004] ", "ms": "[004412] ", "us": "[004412000] "} {
+		buf, logmsg := newBufferLogger(Options{Format: FormatBW, TimeFormat: tf})
+		logmsg("relative time")
+		if !strings.HasPrefix(buf.String(), expected) {
+			t.Errorf("TimeFormat %q: expected prefix %q in %q", tf, expected, buf.String())
+		}
+	}
+}
+
+func TestResetStartTime(t *testing.T) {
+	defer func(clock func() time.Time, startup time.Time) { now = clock; SetStartTime(startup) }(now, StartTime())
+	clock := time.Date(2022, 2, 6, 12, 34, 56, 0, time.Local)
+	now = func() time.Time { return clock }
+
+	ResetStartTime()
+	clock = clock.Add(1500 * time.Millisecond) // sleep 1.5s
+	buf, logmsg := newBufferLogger(Options{Format: FormatBW, TimeFormat: "ms"})
+	logmsg("after reset")
+	if !strings.HasPrefix(buf.String(), "[001500] ") {
+		t.Errorf("expected prefix [001500] in %q", buf.String())
+	}
+
+	buf, logmsg = newBufferLogger(Options{Format: FormatBW, TimeFormat: "s", StartTime: clock.Add(-3 * time.Second)})
+	logmsg("start time option")
+	if !strings.HasPrefix(buf.String(), "[0003] ") {
+		t.Errorf("expected prefix [0003] in %q", buf.String())
+	}
+}
+
+func TestRelativeClockTimestamp(t *testing.T) {
+	defer func(clock func() time.Time, startup time.Time, ts func() time.Time) {
+		now, zerolog.TimestampFunc = clock, ts
+		SetStartTime(startup)
+	}(now, StartTime(), zerolog.TimestampFunc)
+	SetStartTime(time.Date(2022, 2, 6, 12, 34, 56, 0, time.Local))
+	now = func() time.Time { return StartTime().Add(42 * time.Second) }
+	zerolog.TimestampFunc = now
+
+	buf, logmsg := newBufferLogger(Options{Format: FormatBW, TimeFormat: "rel+clock"})
+	logmsg("relative and wall clock")
+	if expected := "[0042] 12:35:38 WRN"; !strings.HasPrefix(buf.String(), expected) {
+		t.Errorf("expected prefix %q in %q", expected, buf.String())
+	}
+}
+
+func TestSetTimeFormat(t *testing.T) {
+	defer func(clock func() time.Time, startup time.Time, ts func() time.Time) {
+		now, zerolog.TimestampFunc = clock, ts
+		SetStartTime(startup)
+	}(now, StartTime(), zerolog.TimestampFunc)
+	defer zconsoleWriter(Options{})
+	SetStartTime(time.Date(2022, 2, 6, 12, 34, 56, 0, time.Local))
+	now = func() time.Time { return StartTime().Add(42 * time.Second) }
+	zerolog.TimestampFunc = now
+
+	var buf bytes.Buffer
+	l := New(Options{Format: FormatBW, TimeFormat: "s", Out: &buf})
+	l.Info().Msg("relative")
+	SetTimeFormat("highres")
+	l.Info().Msg("wall clock")
+	expected := "[0042] INF relative\n2022-02-06 12:35:38.000 INF wall clock\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+	if zerolog.TimeFieldFormat != "2006-01-02 15:04:05.000" {
+		t.Errorf("unexpected TimeFieldFormat %q", zerolog.TimeFieldFormat)
+	}
+}
+
+func TestTimestampPerLogger(t *testing.T) {
+	defer func(ts func() time.Time) { zerolog.TimestampFunc = ts }(zerolog.TimestampFunc)
+	defer zconsoleWriter(Options{})
+	zerolog.TimestampFunc = func() time.Time { return time.Date(2022, 2, 6, 12, 34, 56, 0, time.Local) }
+
+	var first, second bytes.Buffer
+	l1 := New(Options{Format: FormatBW, TimeFormat: "highres", Out: &first})
+	NewTest(Options{TimeFormat: "none"})
+	l2 := New(Options{Format: FormatBW, TimeFormat: "s", Out: &second})
+	l1.Info().Msg("before")
+	SetTimeFormat("default")
+	l1.Info().Msg("after")
+	l2.Info().Msg("changed")
+	if expected := "2022-02-06 12:34:56.000 INF before\n2022-02-06 12:34:56.000 INF after\n"; first.String() != expected {
+		t.Errorf("expected %q, got %q", expected, first.String())
+	}
+	if expected := "2022-02-06 12:34:56 INF changed\n"; second.String() != expected {
+		t.Errorf("expected %q, got %q", expected, second.String())
+	}
+	if zerolog.TimeFieldFormat != zerolog.TimeFormatUnix {
+		t.Errorf("expected unix time in JSON for the default format, got %q", zerolog.TimeFieldFormat)
+	}
+}
+
+func TestRFC3339(t *testing.T) {
+	defer func(clock func() time.Time, local *time.Location) {
+		zerolog.TimestampFunc, time.Local = clock, local
+	}(zerolog.TimestampFunc, time.Local)
+	defer zconsoleWriter(Options{})
+	time.Local = time.FixedZone("UTC+2", 2*60*60)
+	zerolog.TimestampFunc = func() time.Time { return time.Date(2022, 2, 6, 12, 34, 56, 789000000, time.Local) }
+
+	for tf, expected := range map[string]string{"rfc3339": "2022-02-06T12:34:56+02:00", "rfc3339nano": "2022-02-06T12:34:56.789+02:00"} {
+		var buf bytes.Buffer
+		l := New(Options{Format: FormatBW, TimeFormat: tf, Out: &buf})
+		l.Info().Msg("console")
+		if !strings.HasPrefix(buf.String(), expected+" INF") {
+			t.Errorf("TimeFormat %q: expected prefix %q in %q", tf, expected, buf.String())
+		}
+		var js bytes.Buffer
+		tee := TeeWriter(&js, Options{Format: FormatJson})
+		tee.Info().Msg("json")
+		if !strings.Contains(js.String(), `"_zts":"`+expected+`"`) {
+			t.Errorf("TimeFormat %q: expected %q in %q", tf, expected, js.String())
+		}
+	}
+}
+
+func TestUTC(t *testing.T) {
+	defer func(clock func() time.Time, local *time.Location) {
+		zerolog.TimestampFunc, time.Local = clock, local
+	}(zerolog.TimestampFunc, time.Local)
+	time.Local = time.FixedZone("UTC+2", 2*60*60)
+	ts := time.Date(2022, 2, 6, 12, 34, 56, 0, time.UTC)
+	zerolog.TimestampFunc = func() time.Time { return ts.In(time.Local) }
+
+	buf, logmsg := newBufferLogger(Options{Format: FormatBW})
+	logmsg("local time")
+	if !strings.HasPrefix(buf.String(), "2022-02-06 14:34:56 ") {
+		t.Errorf("expected local time in %q", buf.String())
+	}
+
+	buf, logmsg = newBufferLogger(Options{Format: FormatBW, UTC: true})
+	logmsg("utc")
+	if !strings.HasPrefix(buf.String(), "2022-02-06 12:34:56 ") {
+		t.Errorf("expected UTC time in %q", buf.String())
+	}
+
+	buf, logmsg = newBufferLogger(Options{Format: FormatBW, TimeFormat: "highres", UTC: true})
+	logmsg("utc")
+	if !strings.HasPrefix(buf.String(), "2022-02-06 12:34:56.000 ") {
+		t.Errorf("expected UTC time in %q", buf.String())
+	}
+}
+
+func TestEventTimestamp(t *testing.T) {
+	defer func(local *time.Location) { time.Local = local }(time.Local)
+	time.Local = time.FixedZone("UTC+1", 60*60)
+	for tf, event := range map[string]string{
+		"default": `{"_zl":"info","_zts":1644150896,"_zm":"ok"}`,
+		"highres": `{"_zl":"info","_zts":"2022-02-06 13:34:56.123","_zm":"ok"}`,
+	} {
+		var buf bytes.Buffer
+		output := zconsoleWriter(Options{Format: FormatBW, TimeFormat: tf, UTC: true})
+		output.Out = &buf
+		output.Write([]byte(event))
+		if !strings.HasPrefix(buf.String(), time.Date(2022, 2, 6, 12, 34, 56, 0, time.UTC).Format("2006-01-02 15:04:05")) {
+			t.Errorf("TimeFormat %q: expected event time in %q", tf, buf.String())
+		}
+	}
+}
+
+func TestLevelColors(t *testing.T) {
+	buf, logmsg := newBufferLogger(Options{ForceColor: true, LevelColors: map[string]string{"warn": "yellow", "error": Magenta}})
+	logmsg("custom colors")
+	for _, expected := range []string{Yellow + "WRN" + ResetColor, Magenta + "ERR" + ResetColor} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in %q", expected, buf.String())
+		}
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	format := RegisterFormat("brackets", func(i interface{}) string { return "[" + strings.ToUpper(fmt.Sprint(i)) + "]" })
+	buf, logmsg := newBufferLogger(Options{Format: format, TimeFormat: "none"})
+	logmsg("custom format")
+	if !strings.HasPrefix(buf.String(), "[WARN] custom format") {
+		t.Errorf("expected custom level in %q", buf.String())
+	}
+}
+
+func TestTeeDefaultFormat(t *testing.T) {
+	defer func(ts func() time.Time) { zerolog.TimestampFunc = ts }(zerolog.TimestampFunc)
+	zerolog.TimestampFunc = func() time.Time { return time.Date(2022, 2, 6, 12, 34, 56, 0, time.Local) }
+	dir := t.TempDir()
+
+	// without options: BW console format
+	New(Options{Format: FormatColor, ForceColor: true, ColorValues: true, Out: io.Discard})
+	fname := filepath.Join(dir, "default.log")
+	l, closer := TeeWithCloser(fname)
+	l.Warn().Str("file", "hosts").Msg("Creating file")
+	closer.Close()
+	b, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "2022-02-06 12:34:56 WRN Creating file file=hosts\n"; string(b) != expected {
+		t.Errorf("expected %q, got %q", expected, b)
+	}
+
+	// zero value FormatColor: the same as without options
+	fname = filepath.Join(dir, "zero.log")
+	l, closer = TeeWithCloser(fname, Options{})
+	l.Warn().Str("file", "hosts").Msg("Creating file")
+	closer.Close()
+	if b, err = os.ReadFile(fname); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "2022-02-06 12:34:56 WRN Creating file file=hosts\n"; string(b) != expected {
+		t.Errorf("expected %q, got %q", expected, b)
+	}
+
+	// FormatConsole: same as the console
+	New(Options{Format: FormatUnicode, TimeFormat: "none", Out: io.Discard})
+	fname = filepath.Join(dir, "console.log")
+	l, closer = TeeWithCloser(fname, Options{Format: FormatConsole})
+	l.Warn().Str("file", "hosts").Msg("Creating file")
+	closer.Close()
+	if b, err = os.ReadFile(fname); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "🔶 Creating file file=hosts\n"; string(b) != expected {
+		t.Errorf("expected %q, got %q", expected, b)
+	}
+}
+
+func TestTeeOverwrite(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "log.json")
+	tee := func(msg string, overwrite bool) string {
+		l, closer := TeeWithCloser(fname, Options{Format: FormatJson, Overwrite: overwrite})
+		l = l.Output(closer.(io.Writer))
+		l.Error().Msg(msg)
+		closer.Close()
+		b, err := os.ReadFile(fname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	tee("first", false)
+	if content := tee("second", false); !strings.Contains(content, "first") || !strings.Contains(content, "second") {
+		t.Errorf("expected appended content, got %q", content)
+	}
+	if content := tee("third", true); strings.Contains(content, "first") || !strings.Contains(content, "third") {
+		t.Errorf("expected overwritten content, got %q", content)
+	}
+}
+
+func TestNewTestHighres(t *testing.T) {
+	defer func(ts func() time.Time) { zerolog.TimestampFunc = ts }(zerolog.TimestampFunc)
+	zerolog.TimestampFunc = func() time.Time { return time.Date(2022, 2, 6, 12, 34, 56, 789000000, time.Local) }
+	New(Options{Format: FormatBW, Out: io.Discard}) // unix seconds in the global time format
+
+	l, buf := NewTest(Options{Format: FormatBW, TimeFormat: "highres"})
+	l.Info().Msg("test")
+	if expected := "2022-02-06 12:34:56.789 INF test\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestNewTestKeepsGlobals(t *testing.T) {
+	defer SetLevel(GetLevel())
+	var console, file bytes.Buffer
+	New(Options{Format: FormatBW, TimeFormat: "none", Out: &console})
+	SetLevel(0)
+	l, buf := NewTest(Options{Level: 2, TimeFormat: "highres"})
+	if GetLevel() != 0 {
+		t.Errorf("expected level 0, got %d", GetLevel())
+	}
+	tee := TeeWriter(&file)
+	tee.Info().Msg("tee")
+	l.Info().Msg("test")
+	if console.String() != "INF tee\n" || strings.Contains(buf.String(), "tee") {
+		t.Errorf("unexpected console %q and test output %q", console.String(), buf.String())
+	}
+}
+
+func TestNewFile(t *testing.T) {
+	defer SetLevel(GetLevel())
+	fname := filepath.Join(t.TempDir(), "app.log")
+	l, closer, err := NewFile(fname, Options{TimeFormat: "none"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info().Str("file", "hosts").Msg("Creating file")
+	if err := closer.Close(); err != nil {
+		t.Errorf("close: %v", err)
+	}
+	b, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "INF Creating file file=hosts\n"; string(b) != expected {
+		t.Errorf("expected %q without colors, got %q", expected, b)
+	}
+
+	// the console copy of Tee loggers is not written to the file
+	var console, tee bytes.Buffer
+	New(Options{Format: FormatBW, TimeFormat: "none", Out: &console})
+	l, closer, err = NewFile(fname, Options{TimeFormat: "none", Overwrite: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info().Msg("file only")
+	teeLogger := TeeWriter(&tee)
+	teeLogger.Info().Msg("tee message")
+	closer.Close()
+	if b, _ := os.ReadFile(fname); string(b) != "INF file only\n" || console.String() != "INF tee message\n" {
+		t.Errorf("unexpected file %q and console %q", b, console.String())
+	}
+
+	if _, _, err := NewFile(filepath.Join(t.TempDir(), "missing", "app.log"), Options{}); err == nil {
+		t.Errorf("expected error for missing directory")
+	}
+}
+
+func TestNewFilePrecision(t *testing.T) {
+	defer func(ts func() time.Time) { zerolog.TimestampFunc = ts }(zerolog.TimestampFunc)
+	defer func(unit time.Duration, integer bool) {
+		zerolog.DurationFieldUnit, zerolog.DurationFieldInteger = unit, integer
+	}(zerolog.DurationFieldUnit, zerolog.DurationFieldInteger)
+	zerolog.TimestampFunc = func() time.Time { return time.Date(2022, 2, 6, 12, 34, 56, 789000000, time.Local) }
+	New(Options{Format: FormatBW, Out: io.Discard}) // unix seconds and milliseconds in the globals
+
+	fname := filepath.Join(t.TempDir(), "app.log")
+	l, closer, err := NewFile(fname, Options{TimeFormat: "highres", DurationUnit: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info().Dur("elapsed", 1500*time.Millisecond).Msg("done")
+	closer.Close()
+	b, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "2022-02-06 12:34:56.789 INF done elapsed=1.5s\n"; string(b) != expected {
+		t.Errorf("expected %q, got %q", expected, b)
+	}
+}
+
+func TestForensicJSON(t *testing.T) {
+	defer func(drop int) { ZlogDropStack = drop }(ZlogDropStack)
+	ZlogDropStack = 0 // the stack of the test is shorter than the stack of main()
+	var console, forensic, lean bytes.Buffer
+	New(Options{Format: FormatBW, TimeFormat: "none", Out: &console})
+	l := teeLogger(teeFile{&forensic, Options{Format: FormatJson, ForensicJSON: true}}, teeFile{&lean, Options{Format: FormatJson}})
+	l.Error().Err(pkgerrors.WithStack(errors.New("disk full"))).Msg("Cannot write")
+
+	var evt map[string]interface{}
+	if err := json.Unmarshal(forensic.Bytes(), &evt); err != nil {
+		t.Fatalf("%q: %v", forensic.String(), err)
+	}
+	if caller, _ := evt["caller"].(string); !strings.HasPrefix(caller, "zlog_test.go:") {
+		t.Errorf("expected caller in %q", forensic.String())
+	}
+	if _, ok := evt["stack"]; !ok {
+		t.Errorf("expected stack in %q", forensic.String())
+	}
+	for _, out := range []*bytes.Buffer{&console, &lean} {
+		if strings.Contains(out.String(), "stack") || strings.Contains(out.String(), "caller") {
+			t.Errorf("unexpected forensic fields in %q", out.String())
+		}
+	}
+	if expected := "ERR Cannot write error=\"disk full\"\n"; console.String() != expected {
+		t.Errorf("expected %q, got %q", expected, console.String())
+	}
+}
+
+func TestTeeFileLevel(t *testing.T) {
+	defer SetLevel(GetLevel())
+	var console, file bytes.Buffer
+	New(Options{Format: FormatBW, TimeFormat: "none", Out: &console})
+
+	warn := -1
+	l := TeeWriter(&file, Options{Format: FormatJson, FileLevel: &warn})
+	l.Info().Msg("info")
+	l.Warn().Msg("warn")
+	if strings.Contains(file.String(), "info") || !strings.Contains(file.String(), "warn") {
+		t.Errorf("expected only warnings in file %q", file.String())
+	}
+	if !strings.Contains(console.String(), "info") || !strings.Contains(console.String(), "warn") {
+		t.Errorf("expected info and warnings on console %q", console.String())
+	}
+
+	// vice versa: debug messages only in the file
+	console.Reset()
+	file.Reset()
+	debug := 1
+	l = TeeWriter(&file, Options{Format: FormatJson, FileLevel: &debug})
+	l.Debug().Msg("debug")
+	l.Info().Msg("info")
+	if !strings.Contains(file.String(), "debug") || !strings.Contains(file.String(), "info") {
+		t.Errorf("expected debug and info in file %q", file.String())
+	}
+	if strings.Contains(console.String(), "debug") || !strings.Contains(console.String(), "info") {
+		t.Errorf("expected only info on console %q", console.String())
+	}
+}
+
+func TestSequence(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Format: FormatBW, Sequence: true, Out: zerolog.SyncWriter(&buf)})
+	defer New(Options{})
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				l.Info().Int("g", g).Msg("message")
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	seen := map[int]bool{}
+	last := map[int]int{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var g, seq int
+		if _, err := fmt.Sscanf(line[strings.Index(line, "g="):], "g=%d seq=%d", &g, &seq); err != nil {
+			t.Fatalf("cannot parse %q: %s", line, err)
+		}
+		if seen[seq] {
+			t.Errorf("duplicate seq %d", seq)
+		}
+		seen[seq] = true
+		if seq <= last[g] {
+			t.Errorf("goroutine %d: seq %d after %d", g, seq, last[g])
+		}
+		last[g] = seq
+	}
+	if len(seen) != 400 {
+		t.Errorf("expected 400 sequence numbers, got %d", len(seen))
+	}
+
+	var js bytes.Buffer
+	buf.Reset()
+	tee := TeeWriter(&js, Options{Format: FormatJson})
+	tee.Info().Msg("tee")
+	var evt struct {
+		Seq int `json:"seq"`
+	}
+	if err := json.Unmarshal(js.Bytes(), &evt); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), fmt.Sprintf("seq=%d", evt.Seq)) {
+		t.Errorf("expected seq %d on console %q", evt.Seq, buf.String())
+	}
+}
+
+// Run with -race: Tee must not change globals used by concurrent console logging
+func TestTeeConcurrent(t *testing.T) {
+	var buf bytes.Buffer
+	out := zerolog.SyncWriter(&buf)
+	console := New(Options{Format: FormatColor, Out: out})
+	dir := t.TempDir()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				console.Info().Int("j", j).Msg("console")
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				format := FormatBW
+				if j%2 == 0 {
+					format = FormatColor
+				}
+				l, closer := TeeWithCloser(filepath.Join(dir, fmt.Sprintf("log%d.txt", i)), Options{Format: format})
+				l.Info().Int("j", j).Msg("tee")
+				closer.Close()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestFormatJsonStd(t *testing.T) {
+	var buf bytes.Buffer
+	zconsoleWriter(Options{}) // set zlog field names
+	l := zerolog.New(teeOutput(&buf, Options{Format: FormatJsonStd})).With().Timestamp().Logger()
+	l.Warn().Str("file", "hosts").Msg("Creating file")
+
+	var evt map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &evt); err != nil {
+		t.Fatal(err)
+	}
+	for key, expected := range map[string]interface{}{"level": "warn", "message": "Creating file", "file": "hosts"} {
+		if evt[key] != expected {
+			t.Errorf("expected %s=%v in %q", key, expected, buf.String())
+		}
+	}
+	if _, ok := evt["time"]; !ok {
+		t.Errorf("expected time in %q", buf.String())
+	}
+}
+
+func TestHideFields(t *testing.T) {
+	o := Options{Format: FormatBW, TimeFormat: "none", HideFields: []string{"trace_id"}}
+	var console, file bytes.Buffer
+	output := zconsoleWriter(o)
+	output.Out = &console
+	l := zerolog.New(zerolog.MultiLevelWriter(hideFields(output, o.HideFields), teeOutput(&file, Options{Format: FormatJson})))
+	l.Info().Str("trace_id", "4bf92f35").Str("file", "hosts").Msg("Creating file")
+
+	if console.String() != "INF Creating file file=hosts\n" {
+		t.Errorf("unexpected console output %q", console.String())
+	}
+	if !strings.Contains(file.String(), `"trace_id":"4bf92f35"`) {
+		t.Errorf("expected trace_id in %q", file.String())
+	}
+}
+
+func TestCaller(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Caller: true}).Output(&buf)
+	_, _, line, _ := runtime.Caller(0)
+	l.Info().Msg("with caller")
+	expected := fmt.Sprintf(`"caller":"zlog_test.go:%d"`, line+1)
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("expected %s in %q", expected, buf.String())
+	}
+}
+
+// Returns the first frame of the stack of the error
+func topFrame(e *Error) string {
+	return strings.Split(ZMarshalStack(e).(string), " | ")[0]
+}
+
+func newWrappedError(msg string) *Error { return NewErrorSkip(1, msg) }
+
+func TestErrorSkip(t *testing.T) {
+	defer dropTestingStack()()
+	_, _, line, _ := runtime.Caller(0)
+	direct := NewError("direct")
+	wrapped := newWrappedError("wrapped")
+	if top := topFrame(direct); top != fmt.Sprintf("zlog_test.go:%d", line+1) {
+		t.Errorf("unexpected top frame %q for direct error", top)
+	}
+	if top := topFrame(wrapped); top != fmt.Sprintf("zlog_test.go:%d", line+2) {
+		t.Errorf("unexpected top frame %q for wrapped error", top)
+	}
+}
+
+type stackError struct{ st pkgerrors.StackTrace }
+
+func (e stackError) Error() string                    { return "stack error" }
+func (e stackError) StackTrace() pkgerrors.StackTrace { return e.st }
+
+func TestZMarshalStack(t *testing.T) {
+	defer func(drop int) { ZlogDropStack = drop }(ZlogDropStack)
+	ZlogDropStack = 2
+	st := callers(2) // zlog_test.go, testing.go, asm_amd64.s
+
+	for n, expected := range map[int]int{0: 0, 1: 0, 2: 0, 3: 1} {
+		stack := ZMarshalStack(stackError{st[:n]})
+		if expected == 0 {
+			if stack != nil {
+				t.Errorf("%d frames: expected nil, got %q", n, stack)
+			}
+			continue
+		}
+		frames := strings.Split(stack.(string), " | ")
+		if len(frames) != expected || !strings.HasPrefix(frames[0], "zlog_test.go:") {
+			t.Errorf("%d frames: expected %d frames, got %q", n, expected, stack)
+		}
+	}
+	if stack := ZMarshalStack(errors.New("no stack")); stack != nil {
+		t.Errorf("expected nil for error without stack, got %q", stack)
+	}
+}
+
+func TestFormatColorLight(t *testing.T) {
+	buf, logmsg := newBufferLogger(Options{Format: FormatColorLight, ForceColor: true})
+	logmsg("light background")
+	for _, expected := range []string{LightOrange + "WRN" + ResetColor, LightRed + "ERR" + ResetColor, LightCyan + "file="} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in %q", expected, buf.String())
+		}
+	}
+}
+
+func TestTeeGzipFlush(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "log.json.gz")
+	_, closer := TeeWithCloser(fname, Options{Format: FormatJson, Gzip: true})
+	defer closer.Close()
+	l := zerolog.New(teeOutput(closer.(io.Writer), Options{Format: FormatJson}))
+	l.WithLevel(zerolog.FatalLevel).Msg("exiting")
+
+	// like after os.Exit(): the logfile is not closed
+	fd, err := os.Open(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	gz, err := gzip.NewReader(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(gz)
+	if !errors.Is(err, io.ErrUnexpectedEOF) || !strings.Contains(string(b), `"exiting"`) {
+		t.Errorf("expected the message without gzip trailer, got %q, %v", b, err)
+	}
+}
+
+func TestTeeGzip(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "log.json.gz")
+	_, closer := TeeWithCloser(fname, Options{Format: FormatJson, Gzip: true})
+	l := zerolog.New(teeOutput(closer.(io.Writer), Options{Format: FormatJson}))
+	for i := 0; i < 3; i++ {
+		l.Info().Int("i", i).Msg("compressed")
+	}
+	if err := closer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fd, err := os.Open(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	gz, err := gzip.NewReader(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := 0
+	d := json.NewDecoder(gz)
+	for d.More() {
+		var evt map[string]interface{}
+		if err := d.Decode(&evt); err != nil {
+			t.Fatal(err)
+		}
+		if evt["i"] != float64(lines) {
+			t.Errorf("unexpected event %v", evt)
+		}
+		lines++
+	}
+	if lines != 3 {
+		t.Errorf("expected 3 lines, got %d", lines)
+	}
+}
+
+func TestSampleEvery(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Level: 1, SampleEvery: 100}).Output(&buf)
+	for i := 0; i < 1000; i++ {
+		l.Debug().Int("i", i).Send()
+	}
+	l.Warn().Msg("not sampled")
+	if n := strings.Count(buf.String(), "\n"); n != 11 {
+		t.Errorf("expected 10 debug messages and one warning, got %d lines", n)
+	}
+}
+
+type countingHook map[zerolog.Level]int
+
+func (h countingHook) Run(e *zerolog.Event, level zerolog.Level, msg string) { h[level]++ }
+
+func TestHooks(t *testing.T) {
+	hook := countingHook{}
+	l := New(Options{Hooks: []zerolog.Hook{hook}}).Output(io.Discard)
+	l.Debug().Msg("below level")
+	l.Info().Msg("info")
+	l.Error().Msg("error 1")
+	l.Error().Msg("error 2")
+	if hook[zerolog.DebugLevel] != 0 || hook[zerolog.InfoLevel] != 1 || hook[zerolog.ErrorLevel] != 2 {
+		t.Errorf("unexpected hook calls %v", hook)
+	}
+
+	// the hooks of the console logger run for Tee loggers in any format
+	defer zconsoleWriter(Options{})
+	for _, format := range []LogOutputFormat{FormatJson, FormatBW, FormatLogfmt} {
+		hook := countingHook{}
+		New(Options{Hooks: []zerolog.Hook{hook}, Out: io.Discard})
+		tee := TeeWriter(io.Discard, Options{Format: format})
+		tee.Error().Msg("tee")
+		if hook[zerolog.ErrorLevel] != 1 {
+			t.Errorf("%s: expected hook call for Tee logger, got %v", format, hook)
+		}
+	}
+}
+
+func TestErrorNoColor(t *testing.T) {
+	os.Setenv("FORCE_COLOR", "1")
+	defer os.Unsetenv("FORCE_COLOR")
+	err := NewError("x").Str("file", "hosts").Err(io.EOF)
+	if strings.Contains(err.Error(), "\033") {
+		t.Errorf("unexpected color sequences in %q", err.Error())
+	}
+}
+
+func TestErrorColorString(t *testing.T) {
+	err := NewError("x").Str("file", "hosts")
+	if !strings.Contains(err.ColorString(), "\033[") {
+		t.Errorf("expected color sequences in %q", err.ColorString())
+	}
+	if strings.Contains(err.Error(), "\033[") {
+		t.Errorf("unexpected color sequences in %q", err.Error())
+	}
+}
+
+func TestTeeConsoleFields(t *testing.T) {
+	defer zconsoleWriter(Options{})
+	var console, js bytes.Buffer
+	New(Options{Format: FormatBW, TimeFormat: "none", Out: &console, IncludeHostPID: true, Caller: true,
+		BaseFields: map[string]interface{}{"service": "api"}})
+	tee := TeeWriter(&js, Options{Format: FormatJson})
+	tee.Info().Msg("tee")
+
+	var evt map[string]interface{}
+	if err := json.Unmarshal(js.Bytes(), &evt); err != nil {
+		t.Fatal(err)
+	}
+	if evt["service"] != "api" || evt["host"] != hostname || evt["pid"] != float64(os.Getpid()) ||
+		!strings.HasPrefix(fmt.Sprint(evt["caller"]), "zlog_test.go:") {
+		t.Errorf("expected the fields of the console logger in %q", js.String())
+	}
+	if !strings.Contains(console.String(), "service=api") || !strings.Contains(console.String(), "zlog_test.go:") {
+		t.Errorf("expected the fields of the console logger in %q", console.String())
+	}
+}
+
+func TestTeeWithCloserForgotten(t *testing.T) {
+	dir := t.TempDir()
+	count := func() int {
+		teeFilesMu.Lock()
+		defer teeFilesMu.Unlock()
+		return len(teeFiles)
+	}
+	n := count()
+	// a daemon reopening its logfile
+	for i := 0; i < 3; i++ {
+		_, closer := TeeWithCloser(filepath.Join(dir, "app.log"))
+		if err := closer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if count() != n {
+		t.Errorf("expected %d logfiles for Close(), got %d", n, count())
+	}
+}
+
+func TestTeeMulti(t *testing.T) {
+	defer SetLevel(GetLevel())
+	defer zconsoleWriter(Options{})
+	dir := t.TempDir()
+	js, bw := filepath.Join(dir, "log.json"), filepath.Join(dir, "log.txt")
+	for _, fname := range []string{js, bw} {
+		if err := os.WriteFile(fname, []byte("old\n"), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var console bytes.Buffer
+	New(Options{Format: FormatBW, TimeFormat: "none", Out: &console})
+	l := TeeMulti([]TeeTarget{
+		{Filename: js, Options: Options{Format: FormatJson, Overwrite: true}},
+		{Filename: bw, Options: Options{Format: FormatBW}},
+	})
+	l.Debug().Msg("filtered")
+	l.Info().Str("file", "hosts").Msg("Creating file")
+	if err := Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(js)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var evt map[string]interface{}
+	if err := json.Unmarshal(b, &evt); err != nil || evt["_zm"] != "Creating file" {
+		t.Errorf("expected a single JSON event in the overwritten logfile, got %q", b)
+	}
+	if b, _ := os.ReadFile(bw); string(b) != "old\nINF Creating file file=hosts\n" {
+		t.Errorf("expected the BW message appended, got %q", b)
+	}
+	if expected := "INF Creating file file=hosts\n"; console.String() != expected {
+		t.Errorf("expected console %q, got %q", expected, console.String())
+	}
+}